@@ -0,0 +1,125 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/casibase/casibase/object"
+)
+
+// GetEvaluations
+// @Title GetEvaluations
+// @Tag Evaluation API
+// @Description get the evaluation runs of a task
+// @Param owner query string true "The owner of evaluation"
+// @Param task query string true "The task of evaluation"
+// @Success 200 {array} object.Evaluation The Response object
+// @router /get-evaluations [get]
+func (c *ApiController) GetEvaluations() {
+	owner := c.Input().Get("owner")
+	task := c.Input().Get("task")
+
+	evaluations, err := object.GetEvaluations(owner, task)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(evaluations)
+}
+
+// GetEvaluation
+// @Title GetEvaluation
+// @Tag Evaluation API
+// @Description get evaluation
+// @Param id query string true "The id (owner/name) of the evaluation"
+// @Success 200 {object} object.Evaluation The Response object
+// @router /get-evaluation [get]
+func (c *ApiController) GetEvaluation() {
+	id := c.Input().Get("id")
+
+	evaluation, err := object.GetEvaluation(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(evaluation)
+}
+
+// RunEvaluation
+// @Title RunEvaluation
+// @Tag Evaluation API
+// @Description run the golden set of an evaluation task against its store in the background
+// @Param id query string true "The id (owner/name) of the task"
+// @Success 200 {object} object.Evaluation The Response object
+// @router /run-evaluation [post]
+func (c *ApiController) RunEvaluation() {
+	ok := c.RequireAdmin()
+	if !ok {
+		return
+	}
+
+	id := c.Input().Get("id")
+
+	task, err := object.GetTask(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	if task == nil {
+		c.ResponseError(fmt.Sprintf("The task: %s is not found", id))
+		return
+	}
+
+	evaluation, err := object.StartEvaluation(task)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(evaluation)
+}
+
+// DeleteEvaluation
+// @Title DeleteEvaluation
+// @Tag Evaluation API
+// @Description delete evaluation
+// @Param body body object.Evaluation true "The details of the evaluation"
+// @Success 200 {object} controllers.Response The Response object
+// @router /delete-evaluation [post]
+func (c *ApiController) DeleteEvaluation() {
+	ok := c.RequireAdmin()
+	if !ok {
+		return
+	}
+
+	var evaluation object.Evaluation
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &evaluation)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	success, err := object.DeleteEvaluation(&evaluation)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(success)
+}
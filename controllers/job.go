@@ -0,0 +1,173 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/astaxie/beego/utils/pagination"
+	"github.com/casibase/casibase/object"
+	"github.com/casibase/casibase/util"
+)
+
+// GetJobs
+// @Title GetJobs
+// @Tag Job API
+// @Description get jobs
+// @Param owner query string true "The owner of job"
+// @Param store query string false "The store of job"
+// @Success 200 {array} object.Job The Response object
+// @router /get-jobs [get]
+func (c *ApiController) GetJobs() {
+	ok := c.RequireAdmin()
+	if !ok {
+		return
+	}
+
+	owner := c.Input().Get("owner")
+	store := c.Input().Get("store")
+	limit := c.Input().Get("pageSize")
+	page := c.Input().Get("p")
+	field := c.Input().Get("field")
+	value := c.Input().Get("value")
+	sortField := c.Input().Get("sortField")
+	sortOrder := c.Input().Get("sortOrder")
+
+	if limit == "" || page == "" {
+		jobs, err := object.GetJobs(owner, store)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+
+		c.ResponseOk(jobs)
+	} else {
+		limit := util.ParseInt(limit)
+		count, err := object.GetJobCount(owner, field, value)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+
+		paginator := pagination.SetPaginator(c.Ctx, limit, count)
+		jobs, err := object.GetPaginationJobs(owner, paginator.Offset(), limit, field, value, sortField, sortOrder)
+		if err != nil {
+			c.ResponseError(err.Error())
+			return
+		}
+		c.ResponseOk(jobs, paginator.Nums())
+	}
+}
+
+// GetJob
+// @Title GetJob
+// @Tag Job API
+// @Description get job
+// @Param id query string true "The id (owner/name) of the job"
+// @Success 200 {object} object.Job The Response object
+// @router /get-job [get]
+func (c *ApiController) GetJob() {
+	ok := c.RequireAdmin()
+	if !ok {
+		return
+	}
+
+	id := c.Input().Get("id")
+
+	job, err := object.GetJob(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(job)
+}
+
+// CancelJob
+// @Title CancelJob
+// @Tag Job API
+// @Description cancel a running job, it can be resumed later
+// @Param id query string true "The id (owner/name) of the job"
+// @Success 200 {object} controllers.Response The Response object
+// @router /cancel-job [post]
+func (c *ApiController) CancelJob() {
+	ok := c.RequireAdmin()
+	if !ok {
+		return
+	}
+
+	id := c.Input().Get("id")
+
+	success, err := object.CancelJob(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(success)
+}
+
+// ResumeJob
+// @Title ResumeJob
+// @Tag Job API
+// @Description resume a canceled or failed job
+// @Param id query string true "The id (owner/name) of the job"
+// @Success 200 {object} object.Job The Response object
+// @router /resume-job [post]
+func (c *ApiController) ResumeJob() {
+	ok := c.RequireAdmin()
+	if !ok {
+		return
+	}
+
+	id := c.Input().Get("id")
+
+	job, err := object.ResumeJob(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(job)
+}
+
+// DeleteJob
+// @Title DeleteJob
+// @Tag Job API
+// @Description delete job
+// @Param body body object.Job true "The details of the job"
+// @Success 200 {object} controllers.Response The Response object
+// @router /delete-job [post]
+func (c *ApiController) DeleteJob() {
+	ok := c.RequireAdmin()
+	if !ok {
+		return
+	}
+
+	var job object.Job
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &job)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	success, err := object.DeleteJob(&job)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(success)
+}
@@ -15,11 +15,13 @@
 package controllers
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/astaxie/beego"
+	"github.com/casibase/casibase/embedding"
 	"github.com/casibase/casibase/model"
 	"github.com/casibase/casibase/object"
 	"github.com/casibase/casibase/util"
@@ -30,10 +32,24 @@ import (
 // @Tag Message API
 // @Description get message answer
 // @Param id query string true "The id of message"
+// @Param filePrefix query string false "Only search the files whose keys start with the prefix"
+// @Param fileExtensions query string false "Only search the files with the comma-separated extensions, e.g. \"pdf,md\""
+// @Param subject query string false "Only search the files with the subject"
+// @Param startTime query string false "Only search the files collected after the time"
+// @Param endTime query string false "Only search the files collected before the time"
 // @Success 200 {stream} string "An event stream of message answers in JSON format"
 // @router /get-message-answer [get]
 func (c *ApiController) GetMessageAnswer() {
 	id := c.Input().Get("id")
+	filter := &object.SearchFilter{
+		FilePrefix: c.Input().Get("filePrefix"),
+		Subject:    c.Input().Get("subject"),
+		StartTime:  c.Input().Get("startTime"),
+		EndTime:    c.Input().Get("endTime"),
+	}
+	if fileExtensions := c.Input().Get("fileExtensions"); fileExtensions != "" {
+		filter.FileExtensions = strings.Split(fileExtensions, ",")
+	}
 
 	c.Ctx.ResponseWriter.Header().Set("Content-Type", "text/event-stream")
 	c.Ctx.ResponseWriter.Header().Set("Cache-Control", "no-cache")
@@ -85,14 +101,27 @@ func (c *ApiController) GetMessageAnswer() {
 		return
 	}
 
-	store, err := object.GetDefaultStore("admin")
-	if err != nil {
-		c.ResponseErrorStream(message, err.Error())
-		return
-	}
-	if store == nil {
-		c.ResponseErrorStream(message, fmt.Sprintf("The default store is not found"))
-		return
+	var store *object.Store
+	if chat.Store != "" {
+		store, err = object.GetStore(util.GetId("admin", chat.Store))
+		if err != nil {
+			c.ResponseErrorStream(message, err.Error())
+			return
+		}
+		if store == nil {
+			c.ResponseErrorStream(message, fmt.Sprintf("The store: %s is not found", chat.Store))
+			return
+		}
+	} else {
+		store, err = object.GetDefaultStore("admin")
+		if err != nil {
+			c.ResponseErrorStream(message, err.Error())
+			return
+		}
+		if store == nil {
+			c.ResponseErrorStream(message, fmt.Sprintf("The default store is not found"))
+			return
+		}
 	}
 
 	question := store.Welcome
@@ -142,17 +171,94 @@ func (c *ApiController) GetMessageAnswer() {
 		return
 	}
 
-	knowledge, vectorScores, embeddingResult, err := object.GetNearestKnowledge(embeddingProvider, embeddingProviderObj, "admin", question)
-	if err != nil && err.Error() != "no knowledge vectors found" {
+	history, err := object.GetRecentRawMessages(chat.Name, message.CreatedTime, store.MemoryLimit)
+	if err != nil {
 		c.ResponseErrorStream(message, err.Error())
 		return
 	}
 
-	history, err := object.GetRecentRawMessages(chat.Name, message.CreatedTime, store.MemoryLimit)
+	searchQuestion := question
+	var condenseResult *model.ModelResult
+	if questionMessage != nil {
+		searchQuestion, condenseResult, err = object.CondenseQuestion(store, question, history)
+		if err != nil {
+			c.ResponseErrorStream(message, err.Error())
+			return
+		}
+		if searchQuestion != question {
+			fmt.Printf("Condensed question: [%s]\n", searchQuestion)
+			message.CondensedQuestion = searchQuestion
+		}
+	}
+
+	var answerCacheVector []float32
+	var answerCacheVersion string
+	var answerCacheResult *embedding.EmbeddingResult
+	if store.EnableAnswerCache && questionMessage != nil && !filter.IsRestricted() {
+		answerCacheVector, answerCacheResult, err = object.QueryQuestionVector(embeddingProvider, embeddingProviderObj, searchQuestion)
+		if err != nil {
+			c.ResponseErrorStream(message, err.Error())
+			return
+		}
+
+		var answerCache *object.AnswerCache
+		answerCache, answerCacheVersion, err = object.GetAnswerCache(store, embeddingProvider.Name, answerCacheVector)
+		if err != nil {
+			c.ResponseErrorStream(message, err.Error())
+			return
+		}
+
+		if answerCache != nil {
+			fmt.Printf("Question: [%s]\n", question)
+			fmt.Printf("Cached question: [%s], similarity: %f\n", answerCache.Question, answerCache.Similarity)
+
+			questionMessage.TokenCount = answerCacheResult.TokenCount
+			questionMessage.Price = answerCacheResult.Price
+			questionMessage.Currency = answerCacheResult.Currency
+			if condenseResult != nil {
+				questionMessage.TokenCount += condenseResult.TotalTokenCount
+				questionMessage.Price += condenseResult.TotalPrice
+			}
+
+			_, err = object.UpdateMessage(questionMessage.GetId(), questionMessage, false)
+			if err != nil {
+				c.ResponseErrorStream(message, err.Error())
+				return
+			}
+
+			c.ResponseAnswerCacheStream(message, chat, store, answerCache)
+
+			chat.TokenCount += questionMessage.TokenCount
+			chat.Price += questionMessage.Price
+			if chat.Currency == "" {
+				chat.Currency = questionMessage.Currency
+			}
+
+			_, err = object.UpdateChat(chat.GetId(), chat)
+			if err != nil {
+				c.ResponseErrorStream(message, err.Error())
+			}
+			return
+		}
+	}
+
+	retrievalQueries, retrievalResult, err := object.GetRetrievalQueries(store, searchQuestion)
 	if err != nil {
 		c.ResponseErrorStream(message, err.Error())
 		return
 	}
+	if retrievalResult != nil {
+		fmt.Printf("Retrieval queries: %q\n", retrievalQueries)
+		message.RetrievalQueries = retrievalQueries
+		message.RetrievalTokenCount = retrievalResult.TotalTokenCount
+		message.RetrievalPrice = retrievalResult.TotalPrice
+	}
+
+	knowledge, vectorScores, embeddingResult, rerankResult, err := object.GetNearestKnowledge(store, embeddingProvider, embeddingProviderObj, "admin", searchQuestion, retrievalQueries, filter)
+	if err != nil && err.Error() != "no knowledge vectors found" {
+		c.ResponseErrorStream(message, err.Error())
+		return
+	}
 
 	writer := &RefinedWriter{*c.Ctx.ResponseWriter, *NewCleaner(6), []byte{}}
 
@@ -166,6 +272,22 @@ func (c *ApiController) GetMessageAnswer() {
 		questionMessage.TokenCount = embeddingResult.TokenCount
 		questionMessage.Price = embeddingResult.Price
 		questionMessage.Currency = embeddingResult.Currency
+		if rerankResult != nil {
+			questionMessage.TokenCount += rerankResult.TokenCount
+			questionMessage.Price += rerankResult.Price
+		}
+		if condenseResult != nil {
+			questionMessage.TokenCount += condenseResult.TotalTokenCount
+			questionMessage.Price += condenseResult.TotalPrice
+		}
+		if retrievalResult != nil {
+			questionMessage.TokenCount += retrievalResult.TotalTokenCount
+			questionMessage.Price += retrievalResult.TotalPrice
+		}
+		if answerCacheResult != nil {
+			questionMessage.TokenCount += answerCacheResult.TokenCount
+			questionMessage.Price += answerCacheResult.Price
+		}
 
 		_, err = object.UpdateMessage(questionMessage.GetId(), questionMessage, false)
 		if err != nil {
@@ -174,6 +296,13 @@ func (c *ApiController) GetMessageAnswer() {
 		}
 	}
 
+	if len(knowledge) == 0 && questionMessage != nil && store.KnowledgeFallback == "Reply" && store.FallbackReply != "" {
+		fmt.Printf("Question: [%s]\n", question)
+		fmt.Printf("No knowledge found, reply: [%s]\n", store.FallbackReply)
+		c.ResponseTextStream(message, store.FallbackReply)
+		return
+	}
+
 	fmt.Printf("Question: [%s]\n", question)
 	fmt.Printf("Knowledge: [\n")
 	for i, k := range knowledge {
@@ -189,7 +318,39 @@ func (c *ApiController) GetMessageAnswer() {
 		return
 	}
 
-	modelResult, err := modelProviderObj.QueryText(question, writer, history, store.Prompt, knowledge)
+	// only cite the knowledge left in the prompt by the model's token budget
+	if knowledgeLimiter, ok := modelProviderObj.(model.KnowledgeLimiter); ok {
+		knowledge, err = knowledgeLimiter.LimitKnowledgeMessages(question, knowledge)
+		if err != nil {
+			c.ResponseErrorStream(message, err.Error())
+			return
+		}
+		vectorScores = vectorScores[:len(knowledge)]
+	}
+
+	citations, err := object.GetCitations(store, vectorScores)
+	if err != nil {
+		c.ResponseErrorStream(message, err.Error())
+		return
+	}
+	object.AddCitationMarkers(knowledge)
+
+	if len(citations) != 0 {
+		jsonData, err := json.Marshal(citations)
+		if err != nil {
+			c.ResponseErrorStream(message, err.Error())
+			return
+		}
+
+		_, err = c.Ctx.ResponseWriter.Write([]byte(fmt.Sprintf("event: sources\ndata: %s\n\n", jsonData)))
+		if err != nil {
+			c.ResponseErrorStream(message, err.Error())
+			return
+		}
+		writer.Flush()
+	}
+
+	modelResult, err := modelProviderObj.QueryText(question, writer, history, object.GetCitationPrompt(store.Prompt, knowledge), knowledge)
 	if err != nil {
 		c.ResponseErrorStream(message, err.Error())
 		return
@@ -248,12 +409,20 @@ func (c *ApiController) GetMessageAnswer() {
 
 	message.ModelProvider = modelProvider
 	message.VectorScores = vectorScores
+	message.Citations = citations
 	_, err = object.UpdateMessage(message.GetId(), message, false)
 	if err != nil {
 		c.ResponseErrorStream(message, err.Error())
 		return
 	}
 
+	if answerCacheVector != nil && message.Text != "" && modelProvider != "dall-e-3" {
+		_, err = object.AddAnswerCache(store, embeddingProvider.Name, answerCacheVersion, searchQuestion, answerCacheVector, answer, message)
+		if err != nil {
+			fmt.Printf("AddAnswerCache() error: %s\n", err.Error())
+		}
+	}
+
 	if modelProvider == "dall-e-3" {
 		host := c.Ctx.Request.Host
 		origin := getOriginFromHost(host)
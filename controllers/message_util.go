@@ -65,6 +65,86 @@ func (c *ApiController) ResponseErrorStream(message *object.Message, errorText s
 	}
 }
 
+// ResponseTextStream answers the message with a fixed text instead of querying the model.
+func (c *ApiController) ResponseTextStream(message *object.Message, text string) {
+	jsonData, err := ConvertMessageDataToJSON(text)
+	if err != nil {
+		c.ResponseErrorStream(message, err.Error())
+		return
+	}
+
+	event := fmt.Sprintf("event: message\ndata: %s\n\nevent: end\ndata: %s\n\n", jsonData, "end")
+	_, err = c.Ctx.ResponseWriter.Write([]byte(event))
+	if err != nil {
+		c.ResponseErrorStream(message, err.Error())
+		return
+	}
+
+	message.Text = text
+	message.ErrorText = ""
+	message.IsAlerted = false
+	_, err = object.UpdateMessage(message.GetId(), message, false)
+	if err != nil {
+		c.ResponseErrorStream(message, err.Error())
+		return
+	}
+}
+
+// ResponseAnswerCacheStream answers the message with a cached answer instead of querying the model.
+func (c *ApiController) ResponseAnswerCacheStream(message *object.Message, chat *object.Chat, store *object.Store, answerCache *object.AnswerCache) {
+	if len(answerCache.Citations) != 0 {
+		jsonData, err := json.Marshal(answerCache.Citations)
+		if err != nil {
+			c.ResponseErrorStream(message, err.Error())
+			return
+		}
+
+		_, err = c.Ctx.ResponseWriter.Write([]byte(fmt.Sprintf("event: sources\ndata: %s\n\n", jsonData)))
+		if err != nil {
+			c.ResponseErrorStream(message, err.Error())
+			return
+		}
+	}
+
+	jsonData, err := ConvertMessageDataToJSON(answerCache.Answer)
+	if err != nil {
+		c.ResponseErrorStream(message, err.Error())
+		return
+	}
+
+	event := fmt.Sprintf("event: message\ndata: %s\n\nevent: end\ndata: %s\n\n", jsonData, "end")
+	_, err = c.Ctx.ResponseWriter.Write([]byte(event))
+	if err != nil {
+		c.ResponseErrorStream(message, err.Error())
+		return
+	}
+
+	textAnswer := answerCache.Answer
+	textSuggestions := []object.Suggestion{}
+	if store.SuggestionCount != 0 {
+		textAnswer, textSuggestions, err = parseAnswerAndSuggestions(answerCache.Answer)
+		if err != nil {
+			c.ResponseErrorStream(message, err.Error())
+			return
+		}
+	}
+
+	message.Text = textAnswer
+	message.Suggestions = textSuggestions
+	message.ErrorText = ""
+	message.IsAlerted = false
+	message.ModelProvider = answerCache.ModelProvider
+	message.VectorScores = answerCache.VectorScores
+	message.Citations = answerCache.Citations
+	message.IsCached = true
+	message.AnswerCache = answerCache.Name
+	_, err = object.UpdateMessage(message.GetId(), message, false)
+	if err != nil {
+		c.ResponseErrorStream(message, err.Error())
+		return
+	}
+}
+
 func refineQuestionTextViaParsingUrlContent(question string) (string, error) {
 	re := regexp.MustCompile(`href="([^"]+)"`)
 	urls := re.FindStringSubmatch(question)
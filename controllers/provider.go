@@ -16,6 +16,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/astaxie/beego/utils/pagination"
 	"github.com/casibase/casibase/object"
@@ -174,3 +175,58 @@ func (c *ApiController) DeleteProvider() {
 
 	c.ResponseOk(success)
 }
+
+// GetEmbeddingCacheStats
+// @Title GetEmbeddingCacheStats
+// @Tag Provider API
+// @Description get the hits and the saved cost of the embedding cache
+// @Success 200 {object} object.EmbeddingCacheStats The Response object
+// @router /get-embedding-cache-stats [get]
+func (c *ApiController) GetEmbeddingCacheStats() {
+	ok := c.RequireAdmin()
+	if !ok {
+		return
+	}
+
+	stats, err := object.GetEmbeddingCacheStats()
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(stats)
+}
+
+// PurgeEmbeddingCache
+// @Title PurgeEmbeddingCache
+// @Tag Provider API
+// @Description delete the cached embeddings of a provider
+// @Param id query string true "The id (owner/name) of the provider"
+// @Success 200 {object} controllers.Response The Response object
+// @router /purge-embedding-cache [post]
+func (c *ApiController) PurgeEmbeddingCache() {
+	ok := c.RequireAdmin()
+	if !ok {
+		return
+	}
+
+	id := c.Input().Get("id")
+
+	provider, err := object.GetProvider(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	if provider == nil {
+		c.ResponseError(fmt.Sprintf("The provider: %s is not found", id))
+		return
+	}
+
+	affected, err := object.PurgeEmbeddingCache(provider)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(affected)
+}
@@ -0,0 +1,158 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+
+	"github.com/casibase/casibase/object"
+)
+
+// GetQaPairs
+// @Title GetQaPairs
+// @Tag QA Pair API
+// @Description get the generated question/answer pairs of a store
+// @Param owner query string true "The owner of QA pair"
+// @Param store query string true "The store of QA pair"
+// @Success 200 {array} object.QaPair The Response object
+// @router /get-qa-pairs [get]
+func (c *ApiController) GetQaPairs() {
+	ok := c.RequireAdmin()
+	if !ok {
+		return
+	}
+
+	owner := c.Input().Get("owner")
+	store := c.Input().Get("store")
+
+	qaPairs, err := object.GetQaPairs(owner, store)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(qaPairs)
+}
+
+// GetQaPair
+// @Title GetQaPair
+// @Tag QA Pair API
+// @Description get QA pair
+// @Param id query string true "The id (owner/name) of the QA pair"
+// @Success 200 {object} object.QaPair The Response object
+// @router /get-qa-pair [get]
+func (c *ApiController) GetQaPair() {
+	ok := c.RequireAdmin()
+	if !ok {
+		return
+	}
+
+	id := c.Input().Get("id")
+
+	qaPair, err := object.GetQaPair(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(qaPair)
+}
+
+// UpdateQaPair
+// @Title UpdateQaPair
+// @Tag QA Pair API
+// @Description update the question, answer and review state of a QA pair
+// @Param id query string true "The id (owner/name) of the QA pair"
+// @Param body body object.QaPair true "The details of the QA pair"
+// @Success 200 {object} controllers.Response The Response object
+// @router /update-qa-pair [post]
+func (c *ApiController) UpdateQaPair() {
+	ok := c.RequireAdmin()
+	if !ok {
+		return
+	}
+
+	id := c.Input().Get("id")
+
+	var qaPair object.QaPair
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &qaPair)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	success, err := object.UpdateQaPair(id, &qaPair)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(success)
+}
+
+// DeleteQaPair
+// @Title DeleteQaPair
+// @Tag QA Pair API
+// @Description delete QA pair
+// @Param body body object.QaPair true "The details of the QA pair"
+// @Success 200 {object} controllers.Response The Response object
+// @router /delete-qa-pair [post]
+func (c *ApiController) DeleteQaPair() {
+	ok := c.RequireAdmin()
+	if !ok {
+		return
+	}
+
+	var qaPair object.QaPair
+	err := json.Unmarshal(c.Ctx.Input.RequestBody, &qaPair)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	success, err := object.DeleteQaPair(&qaPair)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(success)
+}
+
+// ApproveQaPairs
+// @Title ApproveQaPairs
+// @Tag QA Pair API
+// @Description approve all the pending QA pairs of a store, they are indexed at the next refresh
+// @Param owner query string true "The owner of QA pair"
+// @Param store query string true "The store of QA pair"
+// @Success 200 {object} controllers.Response The Response object
+// @router /approve-qa-pairs [post]
+func (c *ApiController) ApproveQaPairs() {
+	ok := c.RequireAdmin()
+	if !ok {
+		return
+	}
+
+	owner := c.Input().Get("owner")
+	store := c.Input().Get("store")
+
+	affected, err := object.ApproveQaPairs(owner, store)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(affected)
+}
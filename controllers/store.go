@@ -16,6 +16,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/astaxie/beego/utils/pagination"
 	"github.com/casibase/casibase/object"
@@ -198,9 +199,9 @@ func (c *ApiController) DeleteStore() {
 // RefreshStoreVectors
 // @Title RefreshStoreVectors
 // @Tag Store API
-// @Description refresh store vectors
+// @Description start a job to refresh the store vectors incrementally in the background
 // @Param body body object.Store true "The details of the store"
-// @Success 200 {object} controllers.Response The Response object
+// @Success 200 {object} object.Job The Response object
 // @router /refresh-store-vectors [post]
 func (c *ApiController) RefreshStoreVectors() {
 	var store object.Store
@@ -210,11 +211,49 @@ func (c *ApiController) RefreshStoreVectors() {
 		return
 	}
 
-	ok, err := object.RefreshStoreVectors(&store)
+	job, err := object.StartRefreshJob(&store)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(job)
+}
+
+// MigrateStoreEmbeddingProvider
+// @Title MigrateStoreEmbeddingProvider
+// @Tag Store API
+// @Description start a job to embed the store with another embedding provider and switch to it when finished
+// @Param id query string true "The id (owner/name) of the store"
+// @Param embeddingProvider query string true "The name of the new embedding provider"
+// @Param deleteOldVectors query string false "Whether to delete the vectors of the old embedding provider, true or false"
+// @Success 200 {object} object.Job The Response object
+// @router /migrate-store-embedding-provider [post]
+func (c *ApiController) MigrateStoreEmbeddingProvider() {
+	ok := c.RequireAdmin()
+	if !ok {
+		return
+	}
+
+	id := c.Input().Get("id")
+	embeddingProvider := c.Input().Get("embeddingProvider")
+	deleteOldVectors := c.Input().Get("deleteOldVectors") == "true"
+
+	store, err := object.GetStore(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	if store == nil {
+		c.ResponseError(fmt.Sprintf("The store: %s is not found", id))
+		return
+	}
+
+	job, err := object.StartMigrationJob(store, embeddingProvider, deleteOldVectors)
 	if err != nil {
 		c.ResponseError(err.Error())
 		return
 	}
 
-	c.ResponseOk(ok)
+	c.ResponseOk(job)
 }
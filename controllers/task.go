@@ -16,6 +16,8 @@ package controllers
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 
 	"github.com/astaxie/beego/utils/pagination"
 	"github.com/casibase/casibase/object"
@@ -173,3 +175,58 @@ func (c *ApiController) DeleteTask() {
 
 	c.ResponseOk(success)
 }
+
+// UploadTaskGoldenSet
+// @Title UploadTaskGoldenSet
+// @Tag Task API
+// @Description upload the golden set of an evaluation task from a CSV or JSON file
+// @Param id query string true "The id (owner/name) of the task"
+// @Param file formData file true "The golden set file with question, expectedFile, expectedChunk and expectedAnswer columns"
+// @Success 200 {array} object.GoldenQuestion The Response object
+// @router /upload-task-golden-set [post]
+func (c *ApiController) UploadTaskGoldenSet() {
+	ok := c.RequireAdmin()
+	if !ok {
+		return
+	}
+
+	id := c.Input().Get("id")
+
+	file, header, err := c.GetFile("file")
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	goldenSet, err := object.ParseGoldenSet(header.Filename, data)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	task, err := object.GetTask(id)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+	if task == nil {
+		c.ResponseError(fmt.Sprintf("The task: %s is not found", id))
+		return
+	}
+
+	task.GoldenSet = goldenSet
+	_, err = object.UpdateTask(id, task)
+	if err != nil {
+		c.ResponseError(err.Error())
+		return
+	}
+
+	c.ResponseOk(goldenSet)
+}
@@ -166,3 +166,18 @@ func (c *ApiController) DeleteVector() {
 
 	c.ResponseOk(success)
 }
+
+// GetVectorCacheStats
+// @Title GetVectorCacheStats
+// @Tag Vector API
+// @Description get the hit, miss and memory stats of the vector cache
+// @Success 200 {object} object.VectorCacheStats The Response object
+// @router /get-vector-cache-stats [get]
+func (c *ApiController) GetVectorCacheStats() {
+	ok := c.RequireAdmin()
+	if !ok {
+		return
+	}
+
+	c.ResponseOk(object.GetVectorCacheStats())
+}
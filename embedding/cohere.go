@@ -55,11 +55,20 @@ func NewCohereEmbeddingProvider(subType string, inputType string, secretKey stri
 }
 
 func (p *CohereEmbeddingProvider) QueryVector(text string, ctx context.Context) ([]float32, *EmbeddingResult, error) {
+	vectors, embeddingResult, err := p.QueryVectors([]string{text}, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return vectors[0], embeddingResult, nil
+}
+
+func (p *CohereEmbeddingProvider) QueryVectors(texts []string, ctx context.Context) ([][]float32, *EmbeddingResult, error) {
 	client := cohereclient.NewClient(
 		cohereclient.WithToken(p.secretKey),
 	)
 
-	embeddingResult, embed, err := cohereEmbed(ctx, client, &p.subType, &p.inputType, []string{text})
+	embeddingResult, embeds, err := cohereEmbed(ctx, client, &p.subType, &p.inputType, texts)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -69,8 +78,16 @@ func (p *CohereEmbeddingProvider) QueryVector(text string, ctx context.Context)
 		return nil, nil, err
 	}
 
-	vector := float64ToFloat32(embed[0])
-	return vector, embeddingResult, nil
+	vectors := [][]float32{}
+	for _, embed := range embeds {
+		vectors = append(vectors, float64ToFloat32(embed))
+	}
+
+	err = checkEmbeddingCount(vectors, texts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return vectors, embeddingResult, nil
 }
 
 func cohereEmbed(ctx context.Context, client *cohereclient.Client, model *string, inputType *string, texts []string) (*EmbeddingResult, [][]float64, error) {
@@ -73,3 +73,7 @@ func (p *DummyEmbeddingProvider) QueryVector(text string, ctx context.Context) (
 	}
 	return vector, &EmbeddingResult{}, nil
 }
+
+func (p *DummyEmbeddingProvider) QueryVectors(texts []string, ctx context.Context) ([][]float32, *EmbeddingResult, error) {
+	return queryVectorsOneByOne(p, texts, ctx)
+}
@@ -68,3 +68,7 @@ func (p *ErnieEmbeddingProvider) QueryVector(text string, ctx context.Context) (
 	vector := float64ToFloat32(embeddings.Data[0].Embedding)
 	return vector, embeddingResult, nil
 }
+
+func (p *ErnieEmbeddingProvider) QueryVectors(texts []string, ctx context.Context) ([][]float32, *EmbeddingResult, error) {
+	return queryVectorsOneByOne(p, texts, ctx)
+}
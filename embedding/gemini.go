@@ -77,3 +77,40 @@ func (p *GeminiEmbeddingProvider) QueryVector(text string, ctx context.Context)
 	vector := res.Embedding.Values
 	return vector, embeddingResult, nil
 }
+
+func (p *GeminiEmbeddingProvider) QueryVectors(texts []string, ctx context.Context) ([][]float32, *EmbeddingResult, error) {
+	client, err := genai.NewClient(ctx, option.WithAPIKey(p.secretKey))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer client.Close()
+
+	em := client.EmbeddingModel(p.subType)
+	batch := em.NewBatch()
+	for _, text := range texts {
+		batch.AddContent(genai.Text(text))
+	}
+
+	res, err := em.BatchEmbedContents(ctx, batch)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	embeddingResult := &EmbeddingResult{TokenCount: 0}
+
+	err = p.calculatePrice(embeddingResult)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vectors := [][]float32{}
+	for _, embedding := range res.Embeddings {
+		vectors = append(vectors, embedding.Values)
+	}
+
+	err = checkEmbeddingCount(vectors, texts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return vectors, embeddingResult, nil
+}
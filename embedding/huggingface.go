@@ -62,3 +62,7 @@ func (p *HuggingFaceEmbeddingProvider) QueryVector(text string, ctx context.Cont
 	vector := float64ToFloat32(embed[0])
 	return vector, embeddingResult, nil
 }
+
+func (p *HuggingFaceEmbeddingProvider) QueryVectors(texts []string, ctx context.Context) ([][]float32, *EmbeddingResult, error) {
+	return queryVectorsOneByOne(p, texts, ctx)
+}
@@ -90,3 +90,7 @@ func (p *TencentEmbeddingProvider) QueryVector(text string, ctx context.Context)
 
 	return vector, embeddingResult, nil
 }
+
+func (p *TencentEmbeddingProvider) QueryVectors(texts []string, ctx context.Context) ([][]float32, *EmbeddingResult, error) {
+	return queryVectorsOneByOne(p, texts, ctx)
+}
@@ -60,19 +60,30 @@ func (p *JinaEmbeddingProvider) QueryVector(text string, ctx context.Context) ([
 		return nil, nil, fmt.Errorf("text cannot be empty")
 	}
 
+	vectors, embeddingResult, err := p.QueryVectors([]string{text}, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return vectors[0], embeddingResult, nil
+}
+
+func (p *JinaEmbeddingProvider) QueryVectors(texts []string, ctx context.Context) ([][]float32, *EmbeddingResult, error) {
 	url := "https://api.jina.ai/v1/embeddings"
 	token := p.apiKey
 	model := p.subType
 
-	if text == "" {
-		return nil, nil, fmt.Errorf("text can not be empty.")
+	for _, text := range texts {
+		if text == "" {
+			return nil, nil, fmt.Errorf("text can not be empty.")
+		}
 	}
 
 	payload := map[string]interface{}{
 		"model":          model,
 		"normalized":     true,
 		"embedding_type": "float",
-		"input":          []string{text},
+		"input":          texts,
 	}
 
 	reqBody, err := json.Marshal(payload)
@@ -122,7 +133,18 @@ func (p *JinaEmbeddingProvider) QueryVector(text string, ctx context.Context) ([
 	if len(apiResponse.Data) == 0 {
 		return nil, nil, fmt.Errorf("no embeddings found in the response")
 	}
-	embedding := apiResponse.Data[0].Embedding
+
+	vectors := make([][]float32, len(apiResponse.Data))
+	for _, data := range apiResponse.Data {
+		if data.Index >= 0 && data.Index < len(vectors) {
+			vectors[data.Index] = data.Embedding
+		}
+	}
+
+	err = checkEmbeddingCount(vectors, texts)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	embeddingResult := &EmbeddingResult{
 		TokenCount: apiResponse.Usage.TotalTokens,
@@ -133,5 +155,5 @@ func (p *JinaEmbeddingProvider) QueryVector(text string, ctx context.Context) ([
 		return nil, nil, fmt.Errorf("failed to calculate price: %v", err)
 	}
 
-	return embedding, embeddingResult, nil
+	return vectors, embeddingResult, nil
 }
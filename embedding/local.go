@@ -85,6 +85,15 @@ func (p *LocalEmbeddingProvider) calculatePrice(res *EmbeddingResult) error {
 }
 
 func (p *LocalEmbeddingProvider) QueryVector(text string, ctx context.Context) ([]float32, *EmbeddingResult, error) {
+	vectors, embeddingResult, err := p.QueryVectors([]string{text}, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return vectors[0], embeddingResult, nil
+}
+
+func (p *LocalEmbeddingProvider) QueryVectors(texts []string, ctx context.Context) ([][]float32, *EmbeddingResult, error) {
 	var client *openai.Client
 	if p.typ == "Local" {
 		client = getLocalClientFromUrl(p.secretKey, p.providerUrl)
@@ -95,7 +104,7 @@ func (p *LocalEmbeddingProvider) QueryVector(text string, ctx context.Context) (
 	}
 
 	resp, err := client.CreateEmbeddings(ctx, openai.EmbeddingRequest{
-		Input: []string{text},
+		Input: texts,
 		Model: openai.EmbeddingModel(p.subType),
 	})
 	if err != nil {
@@ -110,6 +119,21 @@ func (p *LocalEmbeddingProvider) QueryVector(text string, ctx context.Context) (
 		return nil, nil, err
 	}
 
-	vector := resp.Data[0].Embedding
-	return vector, embeddingResult, nil
+	vectors := getOpenAiEmbeddingVectors(resp.Data)
+	err = checkEmbeddingCount(vectors, texts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return vectors, embeddingResult, nil
+}
+
+// getOpenAiEmbeddingVectors orders the embeddings by their input index.
+func getOpenAiEmbeddingVectors(data []openai.Embedding) [][]float32 {
+	vectors := make([][]float32, len(data))
+	for _, embedding := range data {
+		if embedding.Index >= 0 && embedding.Index < len(vectors) {
+			vectors[embedding.Index] = embedding.Embedding
+		}
+	}
+	return vectors
 }
@@ -133,3 +133,7 @@ func (p *MiniMaxEmbeddingProvider) QueryVector(text string, ctx context.Context)
 
 	return embeddingResponse.Vectors[0], embeddingResult, nil
 }
+
+func (p *MiniMaxEmbeddingProvider) QueryVectors(texts []string, ctx context.Context) ([][]float32, *EmbeddingResult, error) {
+	return queryVectorsOneByOne(p, texts, ctx)
+}
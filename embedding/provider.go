@@ -29,6 +29,7 @@ type EmbeddingResult struct {
 type EmbeddingProvider interface {
 	GetPricing() string
 	QueryVector(text string, ctx context.Context) ([]float32, *EmbeddingResult, error)
+	QueryVectors(texts []string, ctx context.Context) ([][]float32, *EmbeddingResult, error)
 }
 
 func GetEmbeddingProvider(typ string, subType string, clientId string, clientSecret string, providerUrl string, apiVersion string) (EmbeddingProvider, error) {
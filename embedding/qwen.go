@@ -67,10 +67,19 @@ func (p *QwenEmbeddingProvider) calculatePrice(res *EmbeddingResult) error {
 }
 
 func (p *QwenEmbeddingProvider) QueryVector(text string, ctx context.Context) ([]float32, *EmbeddingResult, error) {
+	vectors, embeddingResult, err := p.QueryVectors([]string{text}, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return vectors[0], embeddingResult, nil
+}
+
+func (p *QwenEmbeddingProvider) QueryVectors(texts []string, ctx context.Context) ([][]float32, *EmbeddingResult, error) {
 	var client *openai.Client = getQwenClientFromUrl(p.secretKey, p.providerUrl)
 
 	resp, err := client.CreateEmbeddings(ctx, openai.EmbeddingRequest{
-		Input: []string{text},
+		Input: texts,
 		Model: openai.EmbeddingModel(p.subType),
 	})
 	if err != nil {
@@ -84,6 +93,10 @@ func (p *QwenEmbeddingProvider) QueryVector(text string, ctx context.Context) ([
 		return nil, nil, err
 	}
 
-	vector := resp.Data[0].Embedding
-	return vector, embeddingResult, nil
+	vectors := getOpenAiEmbeddingVectors(resp.Data)
+	err = checkEmbeddingCount(vectors, texts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return vectors, embeddingResult, nil
 }
@@ -14,7 +14,11 @@
 
 package embedding
 
-import "math"
+import (
+	"context"
+	"fmt"
+	"math"
+)
 
 func getPrice(tokenCount int, pricePerThousandTokens float64) float64 {
 	res := (float64(tokenCount) / 1000.0) * pricePerThousandTokens
@@ -29,3 +33,38 @@ func float64ToFloat32(slice []float64) []float32 {
 	}
 	return newSlice
 }
+
+// queryVectorsOneByOne is the QueryVectors of the providers without a batch API.
+func queryVectorsOneByOne(p EmbeddingProvider, texts []string, ctx context.Context) ([][]float32, *EmbeddingResult, error) {
+	vectors := [][]float32{}
+	res := &EmbeddingResult{}
+	for _, text := range texts {
+		vector, embeddingResult, err := p.QueryVector(text, ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		vectors = append(vectors, vector)
+		addEmbeddingResult(res, embeddingResult)
+	}
+	return vectors, res, nil
+}
+
+func addEmbeddingResult(res *EmbeddingResult, embeddingResult *EmbeddingResult) {
+	if embeddingResult == nil {
+		return
+	}
+
+	res.TokenCount += embeddingResult.TokenCount
+	res.Price += embeddingResult.Price
+	if embeddingResult.Currency != "" {
+		res.Currency = embeddingResult.Currency
+	}
+}
+
+func checkEmbeddingCount(vectors [][]float32, texts []string) error {
+	if len(vectors) != len(texts) {
+		return fmt.Errorf("the embedding count: %d doesn't match the text count: %d", len(vectors), len(texts))
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import (
+	"context"
+	"testing"
+)
+
+func TestQueryVectorsOneByOne(t *testing.T) {
+	p, err := NewDummyEmbeddingProvider("")
+	if err != nil {
+		panic(err)
+	}
+
+	texts := []string{"hello", "world", "casibase"}
+	vectors, embeddingResult, err := p.QueryVectors(texts, context.Background())
+	if err != nil {
+		panic(err)
+	}
+
+	if len(vectors) != len(texts) {
+		t.Fatalf("QueryVectors() returned %d vectors, expected %d", len(vectors), len(texts))
+	}
+	for i, text := range texts {
+		vector, _, err := p.QueryVector(text, context.Background())
+		if err != nil {
+			panic(err)
+		}
+		if len(vector) != len(vectors[i]) {
+			t.Errorf("QueryVectors() vector %d has dimension %d, expected %d", i, len(vectors[i]), len(vector))
+		}
+	}
+	if embeddingResult == nil {
+		t.Errorf("QueryVectors() should return the summed embedding result")
+	}
+}
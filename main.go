@@ -32,10 +32,29 @@ func main() {
 	object.CreateTables()
 
 	object.InitDb()
+
+	if object.IsMigratePgvector() {
+		err := object.MigrateVectorsToPgvector()
+		if err != nil {
+			panic(err)
+		}
+		return
+	}
+
 	proxy.InitHttpClient()
 	util.InitIpDb()
 	util.InitParser()
 
+	err := object.ResumeRunningJobs()
+	if err != nil {
+		panic(err)
+	}
+
+	err = object.FailRunningEvaluations()
+	if err != nil {
+		panic(err)
+	}
+
 	beego.InsertFilter("*", beego.BeforeRouter, cors.Allow(&cors.Options{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "DELETE", "PUT", "PATCH", "OPTIONS"},
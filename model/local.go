@@ -234,6 +234,26 @@ func flushDataOpenai(data string, writer io.Writer) error {
 	return nil
 }
 
+func (p *LocalModelProvider) getModel() string {
+	model := p.subType
+	if model == "custom-model" && p.compitableProvider != "" {
+		model = p.compitableProvider
+	} else if model == "custom-model" && p.compitableProvider == "" {
+		model = "gpt-3.5-turbo"
+	}
+	return model
+}
+
+func (p *LocalModelProvider) LimitKnowledgeMessages(question string, knowledgeMessages []*RawMessage) ([]*RawMessage, error) {
+	if getOpenAiModelType(p.subType) != "Chat" || p.subType == "dall-e-3" {
+		return knowledgeMessages, nil
+	}
+
+	model := p.getModel()
+	res, _, err := getKnowledgeMessagesInBudget(question, knowledgeMessages, model, GetOpenAiMaxTokens(model))
+	return res, err
+}
+
 func (p *LocalModelProvider) QueryText(question string, writer io.Writer, history []*RawMessage, prompt string, knowledgeMessages []*RawMessage) (*ModelResult, error) {
 	var client *openai.Client
 	var flushData func(string, io.Writer) error
@@ -254,12 +274,7 @@ func (p *LocalModelProvider) QueryText(question string, writer io.Writer, histor
 		return nil, fmt.Errorf("writer does not implement http.Flusher")
 	}
 
-	model := p.subType
-	if model == "custom-model" && p.compitableProvider != "" {
-		model = p.compitableProvider
-	} else if model == "custom-model" && p.compitableProvider == "" {
-		model = "gpt-3.5-turbo"
-	}
+	model := p.getModel()
 
 	temperature := p.temperature
 	topP := p.topP
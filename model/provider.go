@@ -38,6 +38,12 @@ type ModelProvider interface {
 	QueryText(question string, writer io.Writer, history []*RawMessage, prompt string, knowledgeMessages []*RawMessage) (*ModelResult, error)
 }
 
+// KnowledgeLimiter is implemented by the model providers that drop the knowledge beyond their
+// token budget, it returns the knowledge messages QueryText will actually put in the prompt.
+type KnowledgeLimiter interface {
+	LimitKnowledgeMessages(question string, knowledgeMessages []*RawMessage) ([]*RawMessage, error)
+}
+
 func GetModelProvider(typ string, subType string, clientId string, clientSecret string, temperature float32, topP float32, topK int, frequencyPenalty float32, presencePenalty float32, providerUrl string, apiVersion string, compitableProvider string) (ModelProvider, error) {
 	var p ModelProvider
 	var err error
@@ -129,19 +129,17 @@ func getHistoryMessages(recentMessages []*RawMessage, model string, leftTokens i
 	return res, nil
 }
 
-func OpenaiGenerateMessages(prompt string, question string, recentMessages []*RawMessage, knowledgeMessages []*RawMessage, model string, maxTokens int) ([]*RawMessage, error) {
-	queryMessage := &RawMessage{
-		Text:   question,
-		Author: openai.ChatMessageRoleUser,
-	}
-	queryMessageSize, err := GetTokenSize(model, queryMessage.Text)
+// getKnowledgeMessagesInBudget returns the knowledge messages that fit in the model's maximum
+// token count after the question, and the tokens left for the history.
+func getKnowledgeMessagesInBudget(question string, knowledgeMessages []*RawMessage, model string, maxTokens int) ([]*RawMessage, int, error) {
+	queryMessageSize, err := GetTokenSize(model, question)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	leftTokens := maxTokens - queryMessageSize
 	if leftTokens <= 0 {
-		return nil, fmt.Errorf("the token count: [%d] exceeds the model: [%s]'s maximum token count: [%d]", queryMessageSize, model, maxTokens)
+		return nil, 0, fmt.Errorf("the token count: [%d] exceeds the model: [%s]'s maximum token count: [%d]", queryMessageSize, model, maxTokens)
 	}
 
 	for i, message := range knowledgeMessages {
@@ -152,6 +150,19 @@ func OpenaiGenerateMessages(prompt string, question string, recentMessages []*Ra
 		}
 	}
 
+	return knowledgeMessages, leftTokens, nil
+}
+
+func OpenaiGenerateMessages(prompt string, question string, recentMessages []*RawMessage, knowledgeMessages []*RawMessage, model string, maxTokens int) ([]*RawMessage, error) {
+	queryMessage := &RawMessage{
+		Text:   question,
+		Author: openai.ChatMessageRoleUser,
+	}
+	knowledgeMessages, leftTokens, err := getKnowledgeMessagesInBudget(question, knowledgeMessages, model, maxTokens)
+	if err != nil {
+		return nil, err
+	}
+
 	historyMessages, err := getHistoryMessages(recentMessages, model, leftTokens)
 	if err != nil {
 		return nil, err
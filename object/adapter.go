@@ -33,19 +33,25 @@ var (
 	providerAdapter         *Adapter = nil
 	isCreateDatabaseDefined          = false
 	createDatabase                   = true
+	migratePgvector                  = false
 )
 
 func InitFlag() {
 	if !isCreateDatabaseDefined {
 		isCreateDatabaseDefined = true
-		createDatabase = getCreateDatabaseFlag()
+		createDatabase, migratePgvector = getFlags()
 	}
 }
 
-func getCreateDatabaseFlag() bool {
-	res := flag.Bool("createDatabase", false, "true if you need to create database")
+func getFlags() (bool, bool) {
+	createDatabaseFlag := flag.Bool("createDatabase", false, "true if you need to create database")
+	migratePgvectorFlag := flag.Bool("migratePgvector", false, "true if you need to copy the vectors into the pgvector table and exit")
 	flag.Parse()
-	return *res
+	return *createDatabaseFlag, *migratePgvectorFlag
+}
+
+func IsMigratePgvector() bool {
+	return migratePgvector
 }
 
 func InitConfig() {
@@ -76,6 +82,11 @@ func CreateTables() {
 	}
 
 	adapter.createTable()
+
+	err := migrateVectorData()
+	if err != nil {
+		panic(err)
+	}
 }
 
 // Adapter represents the MySQL adapter for policy storage.
@@ -204,4 +215,29 @@ func (a *Adapter) createTable() {
 	if err != nil {
 		panic(err)
 	}
+
+	err = a.engine.Sync2(new(Job))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.engine.Sync2(new(EmbeddingCache))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.engine.Sync2(new(AnswerCache))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.engine.Sync2(new(Evaluation))
+	if err != nil {
+		panic(err)
+	}
+
+	err = a.engine.Sync2(new(QaPair))
+	if err != nil {
+		panic(err)
+	}
 }
@@ -0,0 +1,266 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/casibase/casibase/embedding"
+	"github.com/casibase/casibase/util"
+)
+
+// EmbeddingCache is the embedding of a text by a provider. Identical texts, like shared
+// boilerplate, duplicated files or the sections of a failed refresh, are embedded once and
+// read from the cache afterwards. The cache is keyed by the provider name, as two providers
+// of the same type and subtype may use different URLs or deployments. Each provider keeps
+// at most embeddingCacheMaxCount entries, the least hit ones are evicted first.
+type EmbeddingCache struct {
+	Provider     string `xorm:"varchar(100) notnull pk" json:"provider"`
+	TextHash     string `xorm:"varchar(100) notnull pk" json:"textHash"`
+	ProviderType string `xorm:"varchar(100)" json:"providerType"`
+	SubType      string `xorm:"varchar(100)" json:"subType"`
+	CreatedTime  string `xorm:"varchar(100)" json:"createdTime"`
+
+	Data       VectorData `xorm:"'data_binary' mediumblob" json:"data"`
+	Dimension  int        `json:"dimension"`
+	TokenCount int        `json:"tokenCount"`
+	Price      float64    `json:"price"`
+	Currency   string     `xorm:"varchar(100)" json:"currency"`
+	HitCount   int        `json:"hitCount"`
+}
+
+type EmbeddingCacheProviderStats struct {
+	Provider        string  `json:"provider"`
+	ProviderType    string  `json:"providerType"`
+	SubType         string  `json:"subType"`
+	EntryCount      int64   `json:"entryCount"`
+	HitCount        int64   `json:"hitCount"`
+	TokenCountSaved int64   `json:"tokenCountSaved"`
+	PriceSaved      float64 `json:"priceSaved"`
+	Currency        string  `json:"currency"`
+}
+
+// EmbeddingCacheStats has the hit and miss counts since the start of the server, and the
+// entries, hits and saved cost of each provider stored in the database.
+type EmbeddingCacheStats struct {
+	HitCount  int64                          `json:"hitCount"`
+	MissCount int64                          `json:"missCount"`
+	Providers []*EmbeddingCacheProviderStats `json:"providers"`
+}
+
+const embeddingCacheMaxCount = 100000
+
+var (
+	embeddingCacheHitCount  int64
+	embeddingCacheMissCount int64
+)
+
+func getEmbeddingCaches(provider *Provider, texts []string) (map[string]*EmbeddingCache, error) {
+	hashes := []string{}
+	for _, text := range texts {
+		hashes = append(hashes, util.GetSha256Hash(text))
+	}
+
+	embeddingCaches := []*EmbeddingCache{}
+	err := adapter.engine.Where("provider = ?", provider.Name).In("text_hash", hashes).Find(&embeddingCaches)
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]*EmbeddingCache{}
+	for _, embeddingCache := range embeddingCaches {
+		res[embeddingCache.TextHash] = embeddingCache
+	}
+	return res, nil
+}
+
+// addEmbeddingCache stores an embedding, a failure only costs a later request, so it is
+// logged instead of failing the embedding.
+func addEmbeddingCache(provider *Provider, text string, data []float32, embeddingResult *embedding.EmbeddingResult) {
+	embeddingCache := &EmbeddingCache{
+		Provider:     provider.Name,
+		ProviderType: provider.Type,
+		SubType:      provider.SubType,
+		TextHash:     util.GetSha256Hash(text),
+		CreatedTime:  util.GetCurrentTime(),
+		Data:         data,
+		Dimension:    len(data),
+	}
+	if embeddingResult != nil {
+		embeddingCache.TokenCount = embeddingResult.TokenCount
+		embeddingCache.Price = embeddingResult.Price
+		embeddingCache.Currency = embeddingResult.Currency
+	}
+
+	// the same text may be embedded by two workers at the same time
+	existed, err := adapter.engine.Exist(&EmbeddingCache{Provider: embeddingCache.Provider, TextHash: embeddingCache.TextHash})
+	if err == nil && !existed {
+		_, err = adapter.engine.Insert(embeddingCache)
+	}
+	if err != nil {
+		fmt.Printf("Failed to add embedding cache for provider: [%s]: %s\n", provider.Name, err.Error())
+	}
+}
+
+// trimEmbeddingCache evicts the least hit and then the oldest entries of a provider beyond
+// embeddingCacheMaxCount. Like adding an entry, a failure is only logged.
+func trimEmbeddingCache(provider *Provider) {
+	count, err := adapter.engine.Where("provider = ?", provider.Name).Count(&EmbeddingCache{})
+	if err == nil && count > embeddingCacheMaxCount {
+		embeddingCaches := []*EmbeddingCache{}
+		err = adapter.engine.Cols("text_hash").Where("provider = ?", provider.Name).Asc("hit_count", "created_time").Limit(int(count - embeddingCacheMaxCount)).Find(&embeddingCaches)
+		if err == nil {
+			hashes := []string{}
+			for _, embeddingCache := range embeddingCaches {
+				hashes = append(hashes, embeddingCache.TextHash)
+			}
+			_, err = adapter.engine.Where("provider = ?", provider.Name).In("text_hash", hashes).Delete(&EmbeddingCache{})
+		}
+	}
+	if err != nil {
+		fmt.Printf("Failed to trim embedding cache for provider: [%s]: %s\n", provider.Name, err.Error())
+	}
+}
+
+func hitEmbeddingCaches(provider *Provider, hashes []string) {
+	atomic.AddInt64(&embeddingCacheHitCount, int64(len(hashes)))
+
+	_, err := adapter.engine.Where("provider = ?", provider.Name).In("text_hash", hashes).Incr("hit_count").Update(&EmbeddingCache{})
+	if err != nil {
+		fmt.Printf("Failed to update embedding cache hits for provider: [%s]: %s\n", provider.Name, err.Error())
+	}
+}
+
+// queryVectorsWithCache embeds the texts not found in the embedding cache in one request
+// and returns the usage of each text. A cached text costs nothing but keeps its estimated
+// token count, as the token count is also the size of the text in a prompt. The saved cost
+// is counted by the hits of the cache. The limiter only waits for the texts sent to the provider.
+func queryVectorsWithCache(ctx context.Context, provider *Provider, embeddingProviderObj embedding.EmbeddingProvider, texts []string, defaultEmbeddingResults []*embedding.EmbeddingResult, limiter *ProviderRateLimiter) ([][]float32, []*embedding.EmbeddingResult, error) {
+	embeddingCaches, err := getEmbeddingCaches(provider, texts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data := make([][]float32, len(texts))
+	embeddingResults := make([]*embedding.EmbeddingResult, len(texts))
+	hitHashes := []string{}
+	missIndexes := []int{}
+	for i, text := range texts {
+		embeddingCache, ok := embeddingCaches[util.GetSha256Hash(text)]
+		if !ok {
+			missIndexes = append(missIndexes, i)
+			continue
+		}
+
+		data[i] = embeddingCache.Data
+		embeddingResults[i] = &embedding.EmbeddingResult{TokenCount: defaultEmbeddingResults[i].TokenCount, Currency: embeddingCache.Currency}
+		hitHashes = append(hitHashes, embeddingCache.TextHash)
+	}
+
+	if len(hitHashes) != 0 {
+		hitEmbeddingCaches(provider, hitHashes)
+	}
+	if len(missIndexes) == 0 {
+		return data, embeddingResults, nil
+	}
+
+	atomic.AddInt64(&embeddingCacheMissCount, int64(len(missIndexes)))
+
+	missTexts := []string{}
+	missDefaultEmbeddingResults := []*embedding.EmbeddingResult{}
+	tokenCount := 0
+	for _, i := range missIndexes {
+		missTexts = append(missTexts, texts[i])
+		missDefaultEmbeddingResults = append(missDefaultEmbeddingResults, defaultEmbeddingResults[i])
+		tokenCount += defaultEmbeddingResults[i].TokenCount
+	}
+
+	err = limiter.wait(ctx, tokenCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	missData, embeddingResult, err := queryVectorsSafe(embeddingProviderObj, missTexts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	missEmbeddingResults := splitEmbeddingResult(embeddingResult, missDefaultEmbeddingResults)
+	for j, i := range missIndexes {
+		data[i] = missData[j]
+		embeddingResults[i] = missEmbeddingResults[j]
+		addEmbeddingCache(provider, texts[i], missData[j], missEmbeddingResults[j])
+	}
+	trimEmbeddingCache(provider)
+
+	return data, embeddingResults, nil
+}
+
+// queryVectorWithCache embeds a single text like a question, which is read from the
+// embedding cache when it was asked before.
+func queryVectorWithCache(provider *Provider, embeddingProviderObj embedding.EmbeddingProvider, text string) ([]float32, *embedding.EmbeddingResult, error) {
+	embeddingCaches, err := getEmbeddingCaches(provider, []string{text})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	embeddingCache, ok := embeddingCaches[util.GetSha256Hash(text)]
+	if ok {
+		hitEmbeddingCaches(provider, []string{embeddingCache.TextHash})
+		return embeddingCache.Data, &embedding.EmbeddingResult{Currency: embeddingCache.Currency}, nil
+	}
+
+	atomic.AddInt64(&embeddingCacheMissCount, 1)
+
+	data, embeddingResult, err := queryVectorSafe(embeddingProviderObj, text)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	addEmbeddingCache(provider, text, data, embeddingResult)
+	trimEmbeddingCache(provider)
+	return data, embeddingResult, nil
+}
+
+func GetEmbeddingCacheStats() (*EmbeddingCacheStats, error) {
+	providers := []*EmbeddingCacheProviderStats{}
+	err := adapter.engine.Table(&EmbeddingCache{}).
+		Select("provider, max(provider_type) as provider_type, max(sub_type) as sub_type, count(*) as entry_count, sum(hit_count) as hit_count, sum(hit_count * token_count) as token_count_saved, sum(hit_count * price) as price_saved, max(currency) as currency").
+		GroupBy("provider").
+		Find(&providers)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &EmbeddingCacheStats{
+		HitCount:  atomic.LoadInt64(&embeddingCacheHitCount),
+		MissCount: atomic.LoadInt64(&embeddingCacheMissCount),
+		Providers: providers,
+	}
+	return res, nil
+}
+
+// PurgeEmbeddingCache deletes the cached embeddings of a provider, e.g. after the model
+// behind it is updated.
+func PurgeEmbeddingCache(provider *Provider) (int64, error) {
+	affected, err := adapter.engine.Where("provider = ?", provider.Name).Delete(&EmbeddingCache{})
+	if err != nil {
+		return 0, err
+	}
+
+	return affected, nil
+}
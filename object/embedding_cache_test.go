@@ -0,0 +1,108 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !skipCi
+// +build !skipCi
+
+package object
+
+import (
+	"context"
+	"testing"
+
+	"github.com/casibase/casibase/embedding"
+)
+
+type countingEmbeddingProvider struct {
+	textCount int
+}
+
+func (p *countingEmbeddingProvider) GetPricing() string {
+	return ""
+}
+
+func (p *countingEmbeddingProvider) QueryVector(text string, ctx context.Context) ([]float32, *embedding.EmbeddingResult, error) {
+	p.textCount += 1
+	return []float32{float32(len(text)), 1}, &embedding.EmbeddingResult{TokenCount: 10, Price: 0.1, Currency: "USD"}, nil
+}
+
+func (p *countingEmbeddingProvider) QueryVectors(texts []string, ctx context.Context) ([][]float32, *embedding.EmbeddingResult, error) {
+	res := [][]float32{}
+	for _, text := range texts {
+		res = append(res, []float32{float32(len(text)), 1})
+	}
+	p.textCount += len(texts)
+	return res, &embedding.EmbeddingResult{TokenCount: 10 * len(texts), Price: 0.1 * float64(len(texts)), Currency: "USD"}, nil
+}
+
+func TestEmbeddingCache(t *testing.T) {
+	InitConfig()
+
+	provider := &Provider{Owner: "admin", Name: "embedding_cache_test", Type: "Test", SubType: "embedding-cache-test"}
+	_, err := PurgeEmbeddingCache(provider)
+	if err != nil {
+		panic(err)
+	}
+
+	embeddingProviderObj := &countingEmbeddingProvider{}
+	limiter := getProviderRateLimiter(provider)
+	texts := []string{"boilerplate", "chapter 1"}
+	defaultEmbeddingResults := []*embedding.EmbeddingResult{{TokenCount: 10}, {TokenCount: 10}}
+
+	_, embeddingResults, err := queryVectorsWithCache(context.Background(), provider, embeddingProviderObj, texts, defaultEmbeddingResults, limiter)
+	if err != nil {
+		panic(err)
+	}
+	if embeddingProviderObj.textCount != 2 || embeddingResults[0].TokenCount != 10 {
+		t.Fatalf("the first request should embed both texts, got %d texts and %d tokens", embeddingProviderObj.textCount, embeddingResults[0].TokenCount)
+	}
+
+	texts = []string{"boilerplate", "chapter 2"}
+	data, embeddingResults, err := queryVectorsWithCache(context.Background(), provider, embeddingProviderObj, texts, defaultEmbeddingResults, limiter)
+	if err != nil {
+		panic(err)
+	}
+	if embeddingProviderObj.textCount != 3 {
+		t.Errorf("only the new text should be embedded, embedded text count = %d", embeddingProviderObj.textCount)
+	}
+	if embeddingResults[0].TokenCount != 10 || embeddingResults[0].Price != 0 || data[0][0] != float32(len("boilerplate")) {
+		t.Errorf("the cached text should cost nothing and keep its token count and embedding, got %v", embeddingResults[0])
+	}
+
+	_, _, err = queryVectorWithCache(provider, embeddingProviderObj, "chapter 1")
+	if err != nil {
+		panic(err)
+	}
+	if embeddingProviderObj.textCount != 3 {
+		t.Errorf("a cached question should not be embedded again, embedded text count = %d", embeddingProviderObj.textCount)
+	}
+
+	stats, err := GetEmbeddingCacheStats()
+	if err != nil {
+		panic(err)
+	}
+	for _, providerStats := range stats.Providers {
+		if providerStats.Provider == provider.Name && (providerStats.EntryCount != 3 || providerStats.HitCount != 2 || providerStats.TokenCountSaved != 20) {
+			t.Errorf("embedding cache stats = %+v, expected 3 entries, 2 hits and 20 tokens saved", providerStats)
+		}
+	}
+
+	affected, err := PurgeEmbeddingCache(provider)
+	if err != nil {
+		panic(err)
+	}
+	if affected != 3 {
+		t.Errorf("purged entry count = %d, expected 3", affected)
+	}
+}
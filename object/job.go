@@ -0,0 +1,611 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/casibase/casibase/storage"
+	"github.com/casibase/casibase/util"
+	"xorm.io/core"
+)
+
+type JobFile struct {
+	Key                  string  `json:"key"`
+	State                string  `json:"state"`
+	SectionCount         int     `json:"sectionCount"`
+	FinishedSectionCount int     `json:"finishedSectionCount"`
+	TokenCount           int     `json:"tokenCount"`
+	Price                float64 `json:"price"`
+	Error                string  `json:"error"`
+}
+
+// Job is a store refresh running in the background. Its progress is saved after each file
+// and at most every jobSaveInterval within a file, so it can be listed while running and
+// resumed after a restart.
+type Job struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	Store        string `xorm:"varchar(100) index" json:"store"`
+	Type         string `xorm:"varchar(100)" json:"type"`
+	State        string `xorm:"varchar(100)" json:"state"`
+	UpdatedTime  string `xorm:"varchar(100)" json:"updatedTime"`
+	FinishedTime string `xorm:"varchar(100)" json:"finishedTime"`
+
+	// the providers of a migration job, the vectors of the old one are kept unless DeleteOldVectors is set
+	OldEmbeddingProvider string `xorm:"varchar(100)" json:"oldEmbeddingProvider"`
+	EmbeddingProvider    string `xorm:"varchar(100)" json:"embeddingProvider"`
+	DeleteOldVectors     bool   `json:"deleteOldVectors"`
+
+	FileCount         int                   `json:"fileCount"`
+	FinishedFileCount int                   `json:"finishedFileCount"`
+	FailedFileCount   int                   `json:"failedFileCount"`
+	Files             []*JobFile            `xorm:"mediumtext" json:"files"`
+	Summary           *VectorRefreshSummary `xorm:"mediumtext" json:"summary"`
+	TokenCount        int                   `json:"tokenCount"`
+	Price             float64               `json:"price"`
+	Currency          string                `xorm:"varchar(100)" json:"currency"`
+	Error             string                `xorm:"mediumtext" json:"error"`
+
+	// the files of a job are refreshed by several workers at the same time
+	mutex     sync.Mutex
+	savedTime time.Time
+}
+
+// jobSaveInterval is the minimum time between two saves of the progress within a file.
+const jobSaveInterval = 3 * time.Second
+
+// jobProgressCols are the columns changed by the progress of a running job.
+var jobProgressCols = []string{"updated_time", "file_count", "finished_file_count", "failed_file_count", "files", "summary", "token_count", "price", "currency"}
+
+var (
+	jobCancelMap   = map[string]context.CancelFunc{}
+	jobCancelMutex sync.Mutex
+)
+
+func GetGlobalJobs() ([]*Job, error) {
+	jobs := []*Job{}
+	err := adapter.engine.Asc("owner").Desc("created_time").Find(&jobs)
+	if err != nil {
+		return jobs, err
+	}
+
+	return jobs, nil
+}
+
+func GetJobs(owner string, store string) ([]*Job, error) {
+	jobs := []*Job{}
+	err := adapter.engine.Desc("created_time").Find(&jobs, &Job{Owner: owner, Store: store})
+	if err != nil {
+		return jobs, err
+	}
+
+	return jobs, nil
+}
+
+func getJob(owner string, name string) (*Job, error) {
+	job := Job{Owner: owner, Name: name}
+	existed, err := adapter.engine.Get(&job)
+	if err != nil {
+		return &job, err
+	}
+
+	if existed {
+		return &job, nil
+	} else {
+		return nil, nil
+	}
+}
+
+func GetJob(id string) (*Job, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getJob(owner, name)
+}
+
+func AddJob(job *Job) (bool, error) {
+	affected, err := adapter.engine.Insert(job)
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func DeleteJob(job *Job) (bool, error) {
+	if isJobRunning(job.GetId()) {
+		return false, fmt.Errorf("The job: %s is running, please cancel it first", job.GetId())
+	}
+
+	affected, err := adapter.engine.ID(core.PK{job.Owner, job.Name}).Delete(&Job{})
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func (job *Job) GetId() string {
+	return fmt.Sprintf("%s/%s", job.Owner, job.Name)
+}
+
+func (job *Job) save() error {
+	if job.Summary != nil {
+		job.Summary.mutex.Lock()
+		defer job.Summary.mutex.Unlock()
+	}
+
+	job.UpdatedTime = util.GetCurrentTime()
+	job.savedTime = time.Now()
+	_, err := adapter.engine.ID(core.PK{job.Owner, job.Name}).AllCols().Update(job)
+	return err
+}
+
+// saveProgress saves the progress columns of a running job. The files list of a large store
+// is big, so unless isForced, it is saved at most once every jobSaveInterval.
+func (job *Job) saveProgress(isForced bool) error {
+	if !isForced && time.Since(job.savedTime) < jobSaveInterval {
+		return nil
+	}
+
+	if job.Summary != nil {
+		job.Summary.mutex.Lock()
+		defer job.Summary.mutex.Unlock()
+	}
+
+	job.UpdatedTime = util.GetCurrentTime()
+	job.savedTime = time.Now()
+	_, err := adapter.engine.ID(core.PK{job.Owner, job.Name}).Cols(jobProgressCols...).Update(job)
+	return err
+}
+
+func (job *Job) getFile(key string) *JobFile {
+	for _, file := range job.Files {
+		if file.Key == key {
+			return file
+		}
+	}
+	return nil
+}
+
+// setFiles lists the files of the refresh, keeping the progress of the files of a previous run.
+func (job *Job) setFiles(files []*storage.Object) error {
+	if job == nil {
+		return nil
+	}
+
+	jobFiles := []*JobFile{}
+	for _, file := range files {
+		jobFile := job.getFile(file.Key)
+		if jobFile == nil || jobFile.State != "Finished" {
+			jobFile = &JobFile{Key: file.Key, State: "Pending"}
+		}
+		jobFiles = append(jobFiles, jobFile)
+	}
+
+	job.Files = jobFiles
+	job.FileCount = len(jobFiles)
+	job.updateFileCounts()
+	return job.saveProgress(true)
+}
+
+func (job *Job) updateFileCounts() {
+	job.FinishedFileCount = 0
+	job.FailedFileCount = 0
+	for _, file := range job.Files {
+		if file.State == "Finished" {
+			job.FinishedFileCount += 1
+		} else if file.State == "Failed" {
+			job.FailedFileCount += 1
+		}
+	}
+}
+
+func (job *Job) getUnfinishedFileCount() int {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+
+	res := 0
+	for _, file := range job.Files {
+		if file.State != "Finished" {
+			res += 1
+		}
+	}
+	return res
+}
+
+func (job *Job) isFileFinished(key string) bool {
+	if job == nil {
+		return false
+	}
+
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+
+	file := job.getFile(key)
+	return file != nil && file.State == "Finished"
+}
+
+func (job *Job) startFile(key string, sectionCount int) error {
+	if job == nil {
+		return nil
+	}
+
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+
+	file := job.getFile(key)
+	if file == nil {
+		return nil
+	}
+
+	file.State = "Running"
+	file.SectionCount = sectionCount
+	file.FinishedSectionCount = 0
+	file.Error = ""
+	return job.saveProgress(false)
+}
+
+// finishSection records a finished section of a file, the vector is nil when the section is skipped.
+func (job *Job) finishSection(key string, vector *Vector) error {
+	if job == nil {
+		return nil
+	}
+
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+
+	file := job.getFile(key)
+	if file == nil {
+		return nil
+	}
+
+	file.FinishedSectionCount += 1
+	if vector != nil {
+		file.TokenCount += vector.TokenCount + vector.SplitTokenCount
+		file.Price += vector.Price
+		job.TokenCount += vector.TokenCount + vector.SplitTokenCount
+		job.Price += vector.Price
+		job.Currency = vector.Currency
+	}
+	return job.saveProgress(false)
+}
+
+func (job *Job) finishFile(key string, err error) error {
+	if job == nil {
+		return nil
+	}
+
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+
+	file := job.getFile(key)
+	if file == nil {
+		return nil
+	}
+
+	if err != nil {
+		file.State = "Failed"
+		file.Error = err.Error()
+	} else {
+		file.State = "Finished"
+	}
+	job.updateFileCounts()
+	return job.saveProgress(true)
+}
+
+func isJobRunning(id string) bool {
+	jobCancelMutex.Lock()
+	defer jobCancelMutex.Unlock()
+
+	_, ok := jobCancelMap[id]
+	return ok
+}
+
+// StartRefreshJob creates a job to refresh the vectors of a store in the background.
+func StartRefreshJob(store *Store) (*Job, error) {
+	job := newStoreJob(store, "Refresh")
+	return startJob(job)
+}
+
+// StartMigrationJob creates a job to embed the files of a store with another embedding
+// provider in the background. The store keeps searching the vectors of its current
+// provider until all files are embedded, then it is switched to the new provider.
+func StartMigrationJob(store *Store, embeddingProviderName string, deleteOldVectors bool) (*Job, error) {
+	oldEmbeddingProvider, err := store.GetEmbeddingProvider()
+	if err != nil {
+		return nil, err
+	}
+	if oldEmbeddingProvider == nil {
+		return nil, fmt.Errorf("The embedding provider for store: %s is not found", store.GetId())
+	}
+
+	embeddingProvider, err := getMigrationEmbeddingProvider(store, embeddingProviderName)
+	if err != nil {
+		return nil, err
+	}
+	if embeddingProvider.Name == oldEmbeddingProvider.Name {
+		return nil, fmt.Errorf("The store: %s already uses the embedding provider: %s", store.GetId(), embeddingProvider.Name)
+	}
+
+	job := newStoreJob(store, "Migrate")
+	job.OldEmbeddingProvider = oldEmbeddingProvider.Name
+	job.EmbeddingProvider = embeddingProvider.Name
+	job.DeleteOldVectors = deleteOldVectors
+	return startJob(job)
+}
+
+func getMigrationEmbeddingProvider(store *Store, embeddingProviderName string) (*Provider, error) {
+	embeddingProvider, err := GetProvider(util.GetIdFromOwnerAndName(store.Owner, embeddingProviderName))
+	if err != nil {
+		return nil, err
+	}
+	if embeddingProvider == nil {
+		return nil, fmt.Errorf("The embedding provider: %s is not found", embeddingProviderName)
+	}
+	if embeddingProvider.Category != "Embedding" {
+		return nil, fmt.Errorf("The provider: %s is not an embedding provider", embeddingProviderName)
+	}
+	return embeddingProvider, nil
+}
+
+func newStoreJob(store *Store, jobType string) *Job {
+	return &Job{
+		Owner:       store.Owner,
+		Name:        fmt.Sprintf("job_%s", util.GetRandomName()),
+		CreatedTime: util.GetCurrentTime(),
+		Store:       store.Name,
+		Type:        jobType,
+		State:       "Running",
+		UpdatedTime: util.GetCurrentTime(),
+		Files:       []*JobFile{},
+		Summary:     &VectorRefreshSummary{},
+	}
+}
+
+func startJob(job *Job) (*Job, error) {
+	jobs, err := GetJobs(job.Owner, job.Store)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, runningJob := range jobs {
+		if runningJob.State == "Running" {
+			return nil, fmt.Errorf("The store: %s already has a running job: %s", util.GetIdFromOwnerAndName(job.Owner, job.Store), runningJob.GetId())
+		}
+	}
+
+	_, err = AddJob(job)
+	if err != nil {
+		return nil, err
+	}
+
+	runJob(job)
+	return job, nil
+}
+
+// ResumeJob runs a canceled or failed job again, the files it finished are skipped and
+// the failed ones are refreshed again.
+func ResumeJob(id string) (*Job, error) {
+	job, err := GetJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, fmt.Errorf("The job: %s is not found", id)
+	}
+
+	if job.State != "Canceled" && job.State != "Failed" {
+		return nil, fmt.Errorf("The job: %s can't be resumed in the state: %s", id, job.State)
+	}
+
+	job.State = "Running"
+	job.Error = ""
+	job.FinishedTime = ""
+	err = job.save()
+	if err != nil {
+		return nil, err
+	}
+
+	runJob(job)
+	return job, nil
+}
+
+func CancelJob(id string) (bool, error) {
+	jobCancelMutex.Lock()
+	cancel, ok := jobCancelMap[id]
+	jobCancelMutex.Unlock()
+
+	if !ok {
+		return false, fmt.Errorf("The job: %s is not running", id)
+	}
+
+	cancel()
+	return true, nil
+}
+
+// ResumeRunningJobs resumes the jobs interrupted by a restart.
+func ResumeRunningJobs() error {
+	jobs, err := GetGlobalJobs()
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if job.State == "Running" {
+			fmt.Printf("Resuming job: [%s] for store: [%s]\n", job.GetId(), job.Store)
+			runJob(job)
+		}
+	}
+
+	return nil
+}
+
+func runJob(job *Job) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	jobCancelMutex.Lock()
+	jobCancelMap[job.GetId()] = cancel
+	jobCancelMutex.Unlock()
+
+	util.SafeGoroutine(func() {
+		defer func() {
+			jobCancelMutex.Lock()
+			delete(jobCancelMap, job.GetId())
+			jobCancelMutex.Unlock()
+			cancel()
+		}()
+
+		var err error
+		if job.Type == "Migrate" {
+			err = runMigrationJob(ctx, job)
+		} else {
+			err = runRefreshJob(ctx, job)
+		}
+
+		if ctx.Err() != nil {
+			job.State = "Canceled"
+		} else if err != nil {
+			job.State = "Failed"
+			job.Error = err.Error()
+		} else if job.FailedFileCount > 0 {
+			// the job can be resumed to refresh the failed files again
+			job.State = "Failed"
+			job.Error = fmt.Sprintf("%d of %d files failed to be refreshed", job.FailedFileCount, job.FileCount)
+		} else {
+			job.State = "Finished"
+		}
+
+		job.FinishedTime = util.GetCurrentTime()
+		err = job.save()
+		if err != nil {
+			fmt.Printf("Failed to save job: [%s]: %s\n", job.GetId(), err.Error())
+		}
+	})
+}
+
+func runRefreshJob(ctx context.Context, job *Job) error {
+	store, err := getStore(job.Owner, job.Store)
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return fmt.Errorf("The store: %s is not found", util.GetIdFromOwnerAndName(job.Owner, job.Store))
+	}
+
+	if job.Summary == nil {
+		job.Summary = &VectorRefreshSummary{}
+	}
+
+	_, err = refreshStoreVectors(ctx, store, job)
+	return err
+}
+
+// runMigrationJob embeds the files with the new provider, switches the store over and
+// deletes the old vectors if asked. Every step can be run again, so a resumed job
+// skips the files already embedded and continues with the remaining steps.
+func runMigrationJob(ctx context.Context, job *Job) error {
+	store, err := getStore(job.Owner, job.Store)
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return fmt.Errorf("The store: %s is not found", util.GetIdFromOwnerAndName(job.Owner, job.Store))
+	}
+
+	if job.Summary == nil {
+		job.Summary = &VectorRefreshSummary{}
+	}
+
+	embeddingProvider, err := getMigrationEmbeddingProvider(store, job.EmbeddingProvider)
+	if err != nil {
+		return err
+	}
+
+	_, err = refreshStoreVectorsWithProvider(ctx, store, embeddingProvider, job)
+	if err != nil {
+		return err
+	}
+
+	// the failed files only have the vectors of the old provider, so the store is kept on it
+	// and the job can be resumed to embed them again
+	unfinishedFileCount := job.getUnfinishedFileCount()
+	if unfinishedFileCount > 0 {
+		return fmt.Errorf("%d of %d files are not embedded with the embedding provider: %s, the store is not switched", unfinishedFileCount, job.FileCount, job.EmbeddingProvider)
+	}
+
+	// the store is read again, so the changes made to it during the job are kept
+	store, err = getStore(job.Owner, job.Store)
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return fmt.Errorf("The store: %s is not found", util.GetIdFromOwnerAndName(job.Owner, job.Store))
+	}
+
+	store.EmbeddingProvider = job.EmbeddingProvider
+	_, err = adapter.engine.ID(core.PK{store.Owner, store.Name}).Cols("embedding_provider").Update(store)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Switched store: [%s] from embedding provider: [%s] to [%s]\n", store.GetId(), job.OldEmbeddingProvider, job.EmbeddingProvider)
+
+	if !job.DeleteOldVectors {
+		return nil
+	}
+
+	return deleteProviderVectors(ctx, store.Name, job.OldEmbeddingProvider, job.Summary)
+}
+
+func deleteProviderVectors(ctx context.Context, storeName string, embeddingProviderName string, summary *VectorRefreshSummary) error {
+	vectors, err := getVectorCache(&Vector{Store: storeName, Provider: embeddingProviderName})
+	if err != nil {
+		return err
+	}
+
+	for _, vector := range vectors {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		_, err = DeleteVector(vector)
+		if err != nil {
+			return err
+		}
+		summary.add(0, 0, 1, 0)
+	}
+
+	return refreshSearchIndexes(storeName)
+}
+
+func GetJobCount(owner string, field, value string) (int64, error) {
+	session := GetSession(owner, -1, -1, field, value, "", "")
+	return session.Count(&Job{})
+}
+
+func GetPaginationJobs(owner string, offset, limit int, field, value, sortField, sortOrder string) ([]*Job, error) {
+	jobs := []*Job{}
+	session := GetSession(owner, offset, limit, field, value, sortField, sortOrder)
+	err := session.Find(&jobs)
+	if err != nil {
+		return jobs, err
+	}
+
+	return jobs, nil
+}
@@ -27,8 +27,12 @@ import (
 )
 
 type VectorScore struct {
-	Vector string  `xorm:"varchar(100)" json:"vector"`
-	Score  float32 `json:"score"`
+	Vector       string  `xorm:"varchar(100)" json:"vector"`
+	Score        float32 `json:"score"`
+	Similarity   float32 `json:"similarity"`
+	KeywordScore float32 `json:"keywordScore"`
+	RerankScore  float32 `json:"rerankScore"`
+	Freshness    float32 `json:"freshness"`
 }
 
 type Suggestion struct {
@@ -41,30 +45,37 @@ type Message struct {
 	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
 	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
 
-	Organization      string        `xorm:"varchar(100)" json:"organization"`
-	User              string        `xorm:"varchar(100) index" json:"user"`
-	Chat              string        `xorm:"varchar(100) index" json:"chat"`
-	ReplyTo           string        `xorm:"varchar(100) index" json:"replyTo"`
-	Author            string        `xorm:"varchar(100)" json:"author"`
-	Text              string        `xorm:"mediumtext" json:"text"`
-	ErrorText         string        `xorm:"mediumtext" json:"errorText"`
-	FileName          string        `xorm:"varchar(100)" json:"fileName"`
-	Comment           string        `xorm:"mediumtext" json:"comment"`
-	TokenCount        int           `json:"tokenCount"`
-	TextTokenCount    int           `json:"textTokenCount"`
-	Price             float64       `json:"price"`
-	Currency          string        `xorm:"varchar(100)" json:"currency"`
-	IsHidden          bool          `json:"isHidden"`
-	IsDeleted         bool          `json:"isDeleted"`
-	NeedNotify        bool          `json:"needNotify"`
-	IsAlerted         bool          `json:"isAlerted"`
-	IsRegenerated     bool          `json:"isRegenerated"`
-	ModelProvider     string        `xorm:"varchar(100)" json:"modelProvider"`
-	EmbeddingProvider string        `xorm:"varchar(100)" json:"embeddingProvider"`
-	VectorScores      []VectorScore `xorm:"mediumtext" json:"vectorScores"`
-	LikeUsers         []string      `json:"likeUsers"`
-	DisLikeUsers      []string      `json:"dislikeUsers"`
-	Suggestions       []Suggestion  `json:"suggestions"`
+	Organization        string        `xorm:"varchar(100)" json:"organization"`
+	User                string        `xorm:"varchar(100) index" json:"user"`
+	Chat                string        `xorm:"varchar(100) index" json:"chat"`
+	ReplyTo             string        `xorm:"varchar(100) index" json:"replyTo"`
+	Author              string        `xorm:"varchar(100)" json:"author"`
+	Text                string        `xorm:"mediumtext" json:"text"`
+	ErrorText           string        `xorm:"mediumtext" json:"errorText"`
+	FileName            string        `xorm:"varchar(100)" json:"fileName"`
+	Comment             string        `xorm:"mediumtext" json:"comment"`
+	TokenCount          int           `json:"tokenCount"`
+	TextTokenCount      int           `json:"textTokenCount"`
+	Price               float64       `json:"price"`
+	Currency            string        `xorm:"varchar(100)" json:"currency"`
+	IsHidden            bool          `json:"isHidden"`
+	IsDeleted           bool          `json:"isDeleted"`
+	NeedNotify          bool          `json:"needNotify"`
+	IsAlerted           bool          `json:"isAlerted"`
+	IsRegenerated       bool          `json:"isRegenerated"`
+	ModelProvider       string        `xorm:"varchar(100)" json:"modelProvider"`
+	EmbeddingProvider   string        `xorm:"varchar(100)" json:"embeddingProvider"`
+	CondensedQuestion   string        `xorm:"mediumtext" json:"condensedQuestion"`
+	RetrievalQueries    []string      `xorm:"mediumtext" json:"retrievalQueries"`
+	RetrievalTokenCount int           `json:"retrievalTokenCount"`
+	RetrievalPrice      float64       `json:"retrievalPrice"`
+	VectorScores        []VectorScore `xorm:"mediumtext" json:"vectorScores"`
+	Citations           []Citation    `xorm:"mediumtext" json:"citations"`
+	IsCached            bool          `json:"isCached"`
+	AnswerCache         string        `xorm:"varchar(100)" json:"answerCache"`
+	LikeUsers           []string      `json:"likeUsers"`
+	DisLikeUsers        []string      `json:"dislikeUsers"`
+	Suggestions         []Suggestion  `json:"suggestions"`
 }
 
 func GetGlobalMessages() ([]*Message, error) {
@@ -153,6 +164,13 @@ func UpdateMessage(id string, message *Message, isHitOnly bool) (bool, error) {
 		message.TextTokenCount = size
 	}
 
+	if originMessage != nil && len(message.DisLikeUsers) > len(originMessage.DisLikeUsers) {
+		_, err = deleteAnswerCachesByMessage(message)
+		if err != nil {
+			return false, err
+		}
+	}
+
 	if isHitOnly {
 		_, err = adapter.engine.ID(core.PK{owner, name}).Cols("suggestions").Update(message)
 	} else {
@@ -319,11 +337,14 @@ func GetAnswer(provider string, question string) (string, *model.ModelResult, er
 		return "", nil, err
 	}
 
+	return getAnswerWithKnowledge(modelProviderObj, question, "", []*model.RawMessage{})
+}
+
+func getAnswerWithKnowledge(modelProviderObj model.ModelProvider, question string, prompt string, knowledge []*model.RawMessage) (string, *model.ModelResult, error) {
 	history := []*model.RawMessage{}
-	knowledge := []*model.RawMessage{}
 	var writer MyWriter
 
-	modelResult, err := modelProviderObj.QueryText(question, &writer, history, "", knowledge)
+	modelResult, err := modelProviderObj.QueryText(question, &writer, history, prompt, knowledge)
 	if err != nil {
 		return "", nil, err
 	}
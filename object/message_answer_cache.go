@@ -0,0 +1,209 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/casibase/casibase/embedding"
+	"github.com/casibase/casibase/util"
+	"xorm.io/core"
+)
+
+const defaultAnswerCacheThreshold = 0.95
+
+// AnswerCache is a previous answer of a store to a question. A new question whose embedding
+// is similar enough to the cached question is answered from the cache instead of the model,
+// as long as the knowledge of the store has not changed since.
+type AnswerCache struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	Store             string        `xorm:"varchar(100) index" json:"store"`
+	EmbeddingProvider string        `xorm:"varchar(100)" json:"embeddingProvider"`
+	KnowledgeVersion  string        `xorm:"varchar(100)" json:"knowledgeVersion"`
+	Question          string        `xorm:"mediumtext" json:"question"`
+	Data              VectorData    `xorm:"'data_binary' mediumblob" json:"data"`
+	Answer            string        `xorm:"mediumtext" json:"answer"`
+	ModelProvider     string        `xorm:"varchar(100)" json:"modelProvider"`
+	VectorScores      []VectorScore `xorm:"mediumtext" json:"vectorScores"`
+	Citations         []Citation    `xorm:"mediumtext" json:"citations"`
+	Message           string        `xorm:"varchar(100) index" json:"message"`
+	HitCount          int           `json:"hitCount"`
+
+	Similarity float32 `xorm:"-" json:"similarity"`
+}
+
+type knowledgeVersion struct {
+	cacheVersion int64
+	version      string
+}
+
+var (
+	knowledgeVersionMap   = map[string]*knowledgeVersion{}
+	knowledgeVersionMutex sync.Mutex
+)
+
+func getStoreAnswerCacheThreshold(store *Store) float32 {
+	if store.AnswerCacheThreshold <= 0 {
+		return defaultAnswerCacheThreshold
+	}
+	return float32(store.AnswerCacheThreshold)
+}
+
+// computeKnowledgeVersion hashes the names and texts of the vectors, so that any added,
+// updated or removed vector changes the version.
+func computeKnowledgeVersion(vectors []*Vector) string {
+	sorted := make([]*Vector, len(vectors))
+	copy(sorted, vectors)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	hash := sha256.New()
+	for _, vector := range sorted {
+		hash.Write([]byte(fmt.Sprintf("%s\n%s\n", vector.Name, util.GetSha256Hash(vector.Text))))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// getKnowledgeVersion returns the version of the knowledge of a store and embedding
+// provider. It is computed again only after the vectors of the store have changed.
+func getKnowledgeVersion(storeName string, embeddingProviderName string) (string, error) {
+	key := getSearchIndexKey(storeName, embeddingProviderName)
+
+	vectorCacheMutex.RLock()
+	cacheVersion := vectorCacheVersions[storeName]
+	vectorCacheMutex.RUnlock()
+
+	knowledgeVersionMutex.Lock()
+	res, ok := knowledgeVersionMap[key]
+	knowledgeVersionMutex.Unlock()
+	if ok && res.cacheVersion == cacheVersion {
+		return res.version, nil
+	}
+
+	vectors, err := getVectorCache(&Vector{Store: storeName, Provider: embeddingProviderName})
+	if err != nil {
+		return "", err
+	}
+
+	res = &knowledgeVersion{cacheVersion: cacheVersion, version: computeKnowledgeVersion(vectors)}
+
+	knowledgeVersionMutex.Lock()
+	knowledgeVersionMap[key] = res
+	knowledgeVersionMutex.Unlock()
+	return res.version, nil
+}
+
+func getNearestAnswerCache(answerCaches []*AnswerCache, qVector []float32, threshold float32) *AnswerCache {
+	var res *AnswerCache
+	qVectorNorm := norm(qVector)
+	for _, answerCache := range answerCaches {
+		if len(answerCache.Data) != len(qVector) {
+			continue
+		}
+
+		similarity := cosineSimilarity(qVector, answerCache.Data, qVectorNorm)
+		if similarity >= threshold && (res == nil || similarity > res.Similarity) {
+			answerCache.Similarity = similarity
+			res = answerCache
+		}
+	}
+	return res
+}
+
+// GetAnswerCache returns the cached answer of the most similar question above the threshold
+// of the store, with the knowledge version the new question would be answered with. The
+// version is also returned on a miss, to save the new answer with AddAnswerCache.
+func GetAnswerCache(store *Store, embeddingProviderName string, qVector []float32) (*AnswerCache, string, error) {
+	version, err := getKnowledgeVersion(store.Name, embeddingProviderName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	answerCaches := []*AnswerCache{}
+	err = adapter.engine.Find(&answerCaches, &AnswerCache{Owner: store.Owner, Store: store.Name, EmbeddingProvider: embeddingProviderName, KnowledgeVersion: version})
+	if err != nil {
+		return nil, "", err
+	}
+
+	answerCache := getNearestAnswerCache(answerCaches, qVector, getStoreAnswerCacheThreshold(store))
+	if answerCache == nil {
+		return nil, version, nil
+	}
+
+	_, err = adapter.engine.ID(core.PK{answerCache.Owner, answerCache.Name}).Incr("hit_count").Update(&AnswerCache{})
+	if err != nil {
+		return nil, "", err
+	}
+
+	answerCache.HitCount += 1
+	return answerCache, version, nil
+}
+
+// AddAnswerCache saves the answer message to a question of the store. Old versions of the
+// knowledge can't be hit anymore, so they are removed at the same time.
+func AddAnswerCache(store *Store, embeddingProviderName string, version string, question string, qVector []float32, answer string, message *Message) (bool, error) {
+	_, err := adapter.engine.Where("owner = ? and store = ? and embedding_provider = ? and knowledge_version <> ?", store.Owner, store.Name, embeddingProviderName, version).Delete(&AnswerCache{})
+	if err != nil {
+		return false, err
+	}
+
+	answerCache := &AnswerCache{
+		Owner:             store.Owner,
+		Name:              fmt.Sprintf("answer_cache_%s", util.GetRandomName()),
+		CreatedTime:       util.GetCurrentTime(),
+		Store:             store.Name,
+		EmbeddingProvider: embeddingProviderName,
+		KnowledgeVersion:  version,
+		Question:          question,
+		Data:              qVector,
+		Answer:            answer,
+		ModelProvider:     message.ModelProvider,
+		VectorScores:      message.VectorScores,
+		Citations:         message.Citations,
+		Message:           message.Name,
+	}
+
+	affected, err := adapter.engine.Insert(answerCache)
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+// deleteAnswerCachesByMessage evicts the cached answer given by the message, or the one
+// the message was answered from.
+func deleteAnswerCachesByMessage(message *Message) (bool, error) {
+	affected, err := adapter.engine.Where("message = ? or name = ?", message.Name, message.AnswerCache).Delete(&AnswerCache{})
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+// QueryQuestionVector embeds a question through the embedding cache, the retrieval of the
+// same question afterwards reads it from the cache.
+func QueryQuestionVector(embeddingProvider *Provider, embeddingProviderObj embedding.EmbeddingProvider, question string) ([]float32, *embedding.EmbeddingResult, error) {
+	return queryVectorWithCache(embeddingProvider, embeddingProviderObj, question)
+}
@@ -0,0 +1,50 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import "testing"
+
+func TestAnswerCache(t *testing.T) {
+	answerCaches := []*AnswerCache{
+		{Name: "answer_cache_1", Question: "How to reset my password?", Data: []float32{1, 0, 0}},
+		{Name: "answer_cache_2", Question: "What does ERR-1042 mean?", Data: []float32{0, 1, 0}},
+		{Name: "answer_cache_3", Question: "Wrong dimension", Data: []float32{1, 0}},
+	}
+
+	answerCache := getNearestAnswerCache(answerCaches, []float32{0.99, 0.05, 0}, 0.95)
+	if answerCache == nil || answerCache.Name != "answer_cache_1" {
+		t.Fatalf("getNearestAnswerCache() = %v, expected answer_cache_1", answerCache)
+	}
+	if answerCache.Similarity < 0.95 {
+		t.Errorf("similarity = %f, expected at least 0.95", answerCache.Similarity)
+	}
+
+	answerCache = getNearestAnswerCache(answerCaches, []float32{0.7, 0.7, 0}, 0.95)
+	if answerCache != nil {
+		t.Errorf("getNearestAnswerCache() = %s, expected no answer above the threshold", answerCache.Name)
+	}
+
+	vectors := []*Vector{{Name: "vector_1", Text: "a"}, {Name: "vector_2", Text: "b"}}
+	version := computeKnowledgeVersion(vectors)
+	if computeKnowledgeVersion([]*Vector{vectors[1], vectors[0]}) != version {
+		t.Errorf("the knowledge version should not depend on the order of the vectors")
+	}
+	if computeKnowledgeVersion([]*Vector{vectors[0], {Name: "vector_2", Text: "c"}}) == version {
+		t.Errorf("the knowledge version should change with the text of a vector")
+	}
+	if computeKnowledgeVersion(vectors[:1]) == version {
+		t.Errorf("the knowledge version should change with a removed vector")
+	}
+}
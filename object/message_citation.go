@@ -0,0 +1,98 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/casibase/casibase/model"
+)
+
+const citationPrompt = "The knowledge is numbered like [1], [2]. When a sentence of your answer uses a piece of knowledge, cite it with its number in square brackets at the end of the sentence, e.g. \"The plan costs 0 [2].\" Do not cite numbers not in the knowledge."
+
+// Citation is a source of an answer, Index is the number of its inline marker like [1].
+type Citation struct {
+	Index      int     `json:"index"`
+	Store      string  `json:"store"`
+	File       string  `json:"file"`
+	ChunkIndex int     `json:"chunkIndex"`
+	Sheet      string  `json:"sheet"`
+	StartRow   int     `json:"startRow"`
+	EndRow     int     `json:"endRow"`
+	Score      float32 `json:"score"`
+	Url        string  `json:"url"`
+}
+
+func getCitationUrl(owner string, storeName string, file string) string {
+	return fmt.Sprintf("/stores/%s/%s/view?file=%s", owner, url.PathEscape(storeName), url.QueryEscape(file))
+}
+
+// GetCitations resolves the vectors of the knowledge to their files, the citations are
+// numbered in the order of the knowledge.
+func GetCitations(store *Store, vectorScores []VectorScore) ([]Citation, error) {
+	res := []Citation{}
+	if len(vectorScores) == 0 {
+		return res, nil
+	}
+
+	vectors, err := getVectorCache(&Vector{Store: store.Name})
+	if err != nil {
+		return nil, err
+	}
+
+	vectorMap := map[string]*Vector{}
+	for _, vector := range vectors {
+		vectorMap[vector.Name] = vector
+	}
+
+	for i, vectorScore := range vectorScores {
+		vector, ok := vectorMap[vectorScore.Vector]
+		if !ok {
+			continue
+		}
+
+		res = append(res, Citation{
+			Index:      i + 1,
+			Store:      store.Name,
+			File:       vector.File,
+			ChunkIndex: vector.Index,
+			Sheet:      vector.Sheet,
+			StartRow:   vector.StartRow,
+			EndRow:     vector.EndRow,
+			Score:      vectorScore.Score,
+			Url:        getCitationUrl(store.Owner, store.Name, vector.File),
+		})
+	}
+	return res, nil
+}
+
+// AddCitationMarkers numbers the knowledge, so the model can cite it by the numbers.
+func AddCitationMarkers(knowledge []*model.RawMessage) {
+	for i, message := range knowledge {
+		message.Text = fmt.Sprintf("[%d] %s", i+1, message.Text)
+	}
+}
+
+// GetCitationPrompt asks the model to cite the numbered knowledge in its answer.
+func GetCitationPrompt(prompt string, knowledge []*model.RawMessage) string {
+	if len(knowledge) == 0 {
+		return prompt
+	}
+	if prompt == "" {
+		return citationPrompt
+	}
+	return prompt + "\n\n" + citationPrompt
+}
@@ -0,0 +1,41 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/casibase/casibase/model"
+)
+
+func TestCitation(t *testing.T) {
+	knowledge := []*model.RawMessage{{Text: "The Basic plan is free."}, {Text: "The Pro plan costs $10."}}
+	AddCitationMarkers(knowledge)
+	if knowledge[0].Text != "[1] The Basic plan is free." || knowledge[1].Text != "[2] The Pro plan costs $10." {
+		t.Errorf("AddCitationMarkers() = [%s, %s]", knowledge[0].Text, knowledge[1].Text)
+	}
+
+	if GetCitationPrompt("You are a helpful assistant.", nil) != "You are a helpful assistant." {
+		t.Errorf("GetCitationPrompt() should keep the prompt without knowledge")
+	}
+	if GetCitationPrompt("", knowledge) != citationPrompt {
+		t.Errorf("GetCitationPrompt() of an empty prompt should be the citation prompt")
+	}
+
+	citationUrl := getCitationUrl("admin", "store-built-in", "docs/plans & pricing.md")
+	if citationUrl != "/stores/admin/store-built-in/view?file=docs%2Fplans+%26+pricing.md" {
+		t.Errorf("getCitationUrl() = %s", citationUrl)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/casibase/casibase/model"
+)
+
+const condenseQuestionPrompt = "Given the following conversation and a follow-up question, rephrase the follow-up question to be a standalone question in its original language, which can be understood without the conversation. Keep the question unchanged if it is standalone already. Only output the standalone question, do not answer it.\n\nConversation:\n%s\nFollow-up question: %s\nStandalone question:"
+
+// getCondenseQuestionPrompt builds the prompt to rewrite a question, the history is
+// ordered from the newest message like the one of GetRecentRawMessages.
+func getCondenseQuestionPrompt(question string, history []*model.RawMessage) string {
+	conversation := ""
+	for i := len(history) - 1; i >= 0; i-- {
+		author := "User"
+		if history[i].Author == "AI" {
+			author = "AI"
+		}
+		conversation += fmt.Sprintf("%s: %s\n", author, history[i].Text)
+	}
+
+	return fmt.Sprintf(condenseQuestionPrompt, conversation, question)
+}
+
+func parseCondensedQuestion(answer string, question string) string {
+	res := strings.TrimSpace(answer)
+	res = strings.TrimPrefix(res, "Standalone question:")
+	res = strings.Trim(strings.TrimSpace(res), "\"")
+	if res == "" {
+		return question
+	}
+	return res
+}
+
+// CondenseQuestion rewrites a follow-up question like "what about the second one?" into
+// a standalone question with the model provider of the store, so the knowledge can be
+// searched without the history. The question is returned unchanged when the store
+// doesn't condense questions or the chat has no history yet.
+func CondenseQuestion(store *Store, question string, history []*model.RawMessage) (string, *model.ModelResult, error) {
+	if !store.CondenseQuestion || len(history) == 0 {
+		return question, nil, nil
+	}
+
+	modelProvider, err := store.GetModelProvider()
+	if err != nil {
+		return "", nil, err
+	}
+	if modelProvider == nil {
+		return "", nil, fmt.Errorf("The model provider for store: %s is not found", store.GetId())
+	}
+
+	modelProviderObj, err := modelProvider.GetModelProvider()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var writer MyWriter
+	modelResult, err := modelProviderObj.QueryText(getCondenseQuestionPrompt(question, history), &writer, []*model.RawMessage{}, "", []*model.RawMessage{})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return parseCondensedQuestion(writer.String(), question), modelResult, nil
+}
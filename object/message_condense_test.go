@@ -0,0 +1,50 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/casibase/casibase/model"
+)
+
+func TestCondenseQuestion(t *testing.T) {
+	history := []*model.RawMessage{
+		{Author: "AI", Text: "The plans are Basic and Pro."},
+		{Author: "admin", Text: "Which plans do you offer?"},
+	}
+
+	prompt := getCondenseQuestionPrompt("what about the second one?", history)
+	expected := "User: Which plans do you offer?\nAI: The plans are Basic and Pro.\n\nFollow-up question: what about the second one?"
+	if !strings.Contains(prompt, expected) {
+		t.Errorf("getCondenseQuestionPrompt() = %q, expected it to contain %q", prompt, expected)
+	}
+
+	question := parseCondensedQuestion(" Standalone question: \"What does the Pro plan include?\"\n", "what about the second one?")
+	if question != "What does the Pro plan include?" {
+		t.Errorf("parseCondensedQuestion() = %q", question)
+	}
+
+	question = parseCondensedQuestion("", "what about the second one?")
+	if question != "what about the second one?" {
+		t.Errorf("parseCondensedQuestion() of an empty answer = %q, expected the original question", question)
+	}
+
+	question, _, err := CondenseQuestion(&Store{}, "what about the second one?", history)
+	if err != nil || question != "what about the second one?" {
+		t.Errorf("CondenseQuestion() should keep the question when disabled, got %q, %v", question, err)
+	}
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/casibase/casibase/embedding"
 	"github.com/casibase/casibase/model"
+	"github.com/casibase/casibase/rerank"
 	"github.com/casibase/casibase/storage"
 	"github.com/casibase/casibase/util"
 	"xorm.io/core"
@@ -44,6 +45,9 @@ type Provider struct {
 	TopK             int     `xorm:"int" json:"topK"`
 	FrequencyPenalty float32 `xorm:"float" json:"frequencyPenalty"`
 	PresencePenalty  float32 `xorm:"float" json:"presencePenalty"`
+
+	RequestsPerMinute int `json:"requestsPerMinute"`
+	TokensPerMinute   int `json:"tokensPerMinute"`
 }
 
 func GetMaskedProvider(provider *Provider, isMaskEnabled bool) *Provider {
@@ -324,6 +328,19 @@ func (p *Provider) GetEmbeddingProvider() (embedding.EmbeddingProvider, error) {
 	return pProvider, nil
 }
 
+func (p *Provider) GetRerankProvider() (rerank.RerankProvider, error) {
+	pProvider, err := rerank.GetRerankProvider(p.Type, p.SubType, p.ClientSecret, p.ProviderUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if pProvider == nil {
+		return nil, fmt.Errorf("the rerank provider type: %s is not supported", p.Type)
+	}
+
+	return pProvider, nil
+}
+
 func GetModelProvidersFromContext(owner string, name string, isFromStore bool) (map[string]*Provider, map[string]model.ModelProvider, error) {
 	providerNames := []string{}
 	if name != "" {
@@ -0,0 +1,95 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// ProviderRateLimiter limits the requests and tokens sent to a provider per minute. It
+// is shared by every store and job using the provider, so their refreshes running at
+// the same time stay within the provider's quota together.
+type ProviderRateLimiter struct {
+	requestsPerMinute int
+	tokensPerMinute   int
+	requestLimiter    *rate.Limiter
+	tokenLimiter      *rate.Limiter
+}
+
+var (
+	providerRateLimiterMap   = map[string]*ProviderRateLimiter{}
+	providerRateLimiterMutex sync.Mutex
+)
+
+func newPerMinuteLimiter(countPerMinute int) *rate.Limiter {
+	if countPerMinute <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	return rate.NewLimiter(rate.Limit(float64(countPerMinute)/60), countPerMinute)
+}
+
+func getProviderRequestsPerMinute(provider *Provider) int {
+	if provider.RequestsPerMinute > 0 {
+		return provider.RequestsPerMinute
+	}
+
+	// the free tier limit of OpenAI embeddings
+	if provider.Type == "OpenAI" {
+		return 3
+	}
+	return 0
+}
+
+// getProviderRateLimiter returns the limiter of a provider, it is created again when the
+// limits of the provider are changed.
+func getProviderRateLimiter(provider *Provider) *ProviderRateLimiter {
+	requestsPerMinute := getProviderRequestsPerMinute(provider)
+	tokensPerMinute := provider.TokensPerMinute
+
+	providerRateLimiterMutex.Lock()
+	defer providerRateLimiterMutex.Unlock()
+
+	key := provider.GetId()
+	limiter, ok := providerRateLimiterMap[key]
+	if ok && limiter.requestsPerMinute == requestsPerMinute && limiter.tokensPerMinute == tokensPerMinute {
+		return limiter
+	}
+
+	limiter = &ProviderRateLimiter{
+		requestsPerMinute: requestsPerMinute,
+		tokensPerMinute:   tokensPerMinute,
+		requestLimiter:    newPerMinuteLimiter(requestsPerMinute),
+		tokenLimiter:      newPerMinuteLimiter(tokensPerMinute),
+	}
+	providerRateLimiterMap[key] = limiter
+	return limiter
+}
+
+// wait blocks until a request with the estimated token count can be sent.
+func (limiter *ProviderRateLimiter) wait(ctx context.Context, tokenCount int) error {
+	err := limiter.requestLimiter.Wait(ctx)
+	if err != nil {
+		return err
+	}
+
+	// a request larger than the limit is sent when the limiter is full
+	if limiter.tokensPerMinute > 0 && tokenCount > limiter.tokensPerMinute {
+		tokenCount = limiter.tokensPerMinute
+	}
+	return limiter.tokenLimiter.WaitN(ctx, tokenCount)
+}
@@ -0,0 +1,59 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestProviderRateLimiter(t *testing.T) {
+	provider := &Provider{Owner: "admin", Name: "provider_limiter_test", Type: "OpenAI"}
+	limiter := getProviderRateLimiter(provider)
+	if limiter.requestsPerMinute != 3 {
+		t.Errorf("requests per minute of OpenAI = %d, expected 3", limiter.requestsPerMinute)
+	}
+	if getProviderRateLimiter(provider) != limiter {
+		t.Errorf("the limiter should be shared by the users of a provider")
+	}
+
+	provider.RequestsPerMinute = 60
+	provider.TokensPerMinute = 100
+	limiter = getProviderRateLimiter(provider)
+	if limiter.requestsPerMinute != 60 || limiter.tokensPerMinute != 100 {
+		t.Fatalf("the limiter should be created again after the limits are changed")
+	}
+
+	// a request larger than the token limit still passes when the limiter is full
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := limiter.wait(ctx, 1000)
+	if err != nil {
+		t.Fatalf("wait() error = %v", err)
+	}
+
+	// the tokens are used up, so the next request would exceed the deadline
+	err = limiter.wait(ctx, 100)
+	if err == nil {
+		t.Errorf("wait() should fail when the tokens per minute are used up")
+	}
+
+	provider = &Provider{Owner: "admin", Name: "provider_limiter_test_2", Type: "Local"}
+	err = getProviderRateLimiter(provider).wait(ctx, 1000000)
+	if err != nil {
+		t.Errorf("wait() of an unlimited provider error = %v", err)
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/casibase/casibase/split"
+	"github.com/casibase/casibase/storage"
+	"github.com/casibase/casibase/util"
+	"xorm.io/core"
+)
+
+// QaPair is a question/answer pair generated from a section of a file by the "Generated QA"
+// splitter. The pairs are "Pending" until an admin reviews them, only the "Approved" ones are
+// indexed at the next refresh of the store.
+type QaPair struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	Store      string  `xorm:"varchar(100) index" json:"store"`
+	File       string  `xorm:"varchar(100)" json:"file"`
+	Index      int     `json:"index"`
+	Source     string  `xorm:"mediumtext" json:"source"`
+	Question   string  `xorm:"mediumtext" json:"question"`
+	Answer     string  `xorm:"mediumtext" json:"answer"`
+	State      string  `xorm:"varchar(100)" json:"state"`
+	TokenCount int     `json:"tokenCount"`
+	Price      float64 `json:"price"`
+	Currency   string  `xorm:"varchar(100)" json:"currency"`
+
+	FileModifiedTime string `xorm:"varchar(100)" json:"fileModifiedTime"`
+	FileSize         int64  `json:"fileSize"`
+	FileHash         string `xorm:"varchar(100)" json:"fileHash"`
+	Splitter         string `xorm:"varchar(100)" json:"splitter"`
+}
+
+func GetQaPairs(owner string, store string) ([]*QaPair, error) {
+	qaPairs := []*QaPair{}
+	err := adapter.engine.Find(&qaPairs, &QaPair{Owner: owner, Store: store})
+	if err != nil {
+		return qaPairs, err
+	}
+
+	sort.SliceStable(qaPairs, func(i, j int) bool {
+		if qaPairs[i].File != qaPairs[j].File {
+			return qaPairs[i].File < qaPairs[j].File
+		}
+		return qaPairs[i].Index < qaPairs[j].Index
+	})
+	return qaPairs, nil
+}
+
+func getQaPair(owner string, name string) (*QaPair, error) {
+	qaPair := QaPair{Owner: owner, Name: name}
+	existed, err := adapter.engine.Get(&qaPair)
+	if err != nil {
+		return &qaPair, err
+	}
+
+	if existed {
+		return &qaPair, nil
+	} else {
+		return nil, nil
+	}
+}
+
+func GetQaPair(id string) (*QaPair, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getQaPair(owner, name)
+}
+
+// UpdateQaPair saves the review of a pair, the file information is kept from the generation.
+func UpdateQaPair(id string, qaPair *QaPair) (bool, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	p, err := getQaPair(owner, name)
+	if err != nil {
+		return false, err
+	}
+	if p == nil {
+		return false, nil
+	}
+
+	_, err = adapter.engine.ID(core.PK{owner, name}).Cols("question", "answer", "state").Update(qaPair)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func DeleteQaPair(qaPair *QaPair) (bool, error) {
+	affected, err := adapter.engine.ID(core.PK{qaPair.Owner, qaPair.Name}).Delete(&QaPair{})
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+// ApproveQaPairs approves all the pending pairs of a store.
+func ApproveQaPairs(owner string, store string) (int64, error) {
+	affected, err := adapter.engine.Where("owner = ? and store = ? and state = ?", owner, store, "Pending").Cols("state").Update(&QaPair{State: "Approved"})
+	if err != nil {
+		return 0, err
+	}
+
+	return affected, nil
+}
+
+func (qaPair *QaPair) GetId() string {
+	return fmt.Sprintf("%s/%s", qaPair.Owner, qaPair.Name)
+}
+
+// isFileQaGenerated returns whether the pairs of a file were generated from the same version
+// of it in the storage and with the same split settings.
+func isFileQaGenerated(file *storage.Object, qaPairs []*QaPair, splitter string) bool {
+	if len(qaPairs) == 0 || file.LastModified == "" {
+		return false
+	}
+
+	for _, qaPair := range qaPairs {
+		if qaPair.FileModifiedTime != file.LastModified || qaPair.FileSize != file.Size || qaPair.Splitter != splitter {
+			return false
+		}
+	}
+	return true
+}
+
+// replaceFileQaPairs replaces the pairs of an older version of a file by the newly generated
+// ones, which wait for a review again.
+func replaceFileQaPairs(storeName string, file *storage.Object, fileHash string, splitter string, textSections []*split.TextSection) ([]*QaPair, error) {
+	_, err := adapter.engine.Delete(&QaPair{Owner: "admin", Store: storeName, File: file.Key})
+	if err != nil {
+		return nil, err
+	}
+
+	res := []*QaPair{}
+	for i, textSection := range textSections {
+		qaPair := &QaPair{
+			Owner:            "admin",
+			Name:             fmt.Sprintf("qa_pair_%s", util.GetRandomName()),
+			CreatedTime:      util.GetCurrentTime(),
+			Store:            storeName,
+			File:             file.Key,
+			Index:            i,
+			Source:           textSection.Source,
+			Question:         textSection.Question,
+			Answer:           textSection.Text,
+			State:            "Pending",
+			TokenCount:       textSection.TokenCount,
+			Price:            textSection.Price,
+			Currency:         textSection.Currency,
+			FileModifiedTime: file.LastModified,
+			FileSize:         file.Size,
+			FileHash:         fileHash,
+			Splitter:         splitter,
+		}
+		res = append(res, qaPair)
+	}
+
+	if len(res) != 0 {
+		_, err = adapter.engine.Insert(res)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// getFileQaSections returns the approved pairs of a file as its sections. The pairs are only
+// generated when the file is new or changed, so the reviews are kept between the refreshes.
+func getFileQaSections(storeName string, file *storage.Object, splitConfig *split.SplitConfig, splitter string) ([]*split.TextSection, string, error) {
+	qaPairs := []*QaPair{}
+	err := adapter.engine.Asc("index").Find(&qaPairs, &QaPair{Owner: "admin", Store: storeName, File: file.Key})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !isFileQaGenerated(file, qaPairs, splitter) {
+		fmt.Printf("Generating QA pairs for store: [%s], file: [%s]\n", storeName, file.Key)
+		textSections, fileHash, err := getFileTextSections(file, splitConfig)
+		if err != nil {
+			return nil, "", err
+		}
+
+		qaPairs, err = replaceFileQaPairs(storeName, file, fileHash, splitter, textSections)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	res := []*split.TextSection{}
+	fileHash := ""
+	for _, qaPair := range qaPairs {
+		fileHash = qaPair.FileHash
+		if qaPair.State == "Approved" {
+			res = append(res, &split.TextSection{Question: qaPair.Question, Text: qaPair.Answer})
+		}
+	}
+	return res, fileHash, nil
+}
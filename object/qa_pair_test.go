@@ -0,0 +1,41 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/casibase/casibase/storage"
+)
+
+func TestIsFileQaGenerated(t *testing.T) {
+	file := &storage.Object{Key: "a.md", LastModified: "2024-01-02T00:00:00Z", Size: 100}
+	qaPairs := []*QaPair{
+		{Index: 0, State: "Approved", FileModifiedTime: "2024-01-02T00:00:00Z", FileSize: 100, Splitter: "Generated QA/0/0/0/"},
+		{Index: 1, State: "Pending", FileModifiedTime: "2024-01-02T00:00:00Z", FileSize: 100, Splitter: "Generated QA/0/0/0/"},
+	}
+	if !isFileQaGenerated(file, qaPairs, "Generated QA/0/0/0/") {
+		t.Errorf("isFileQaGenerated() = false, expected true for the same version")
+	}
+
+	if isFileQaGenerated(file, qaPairs, "Generated QA/300/0/0/") {
+		t.Errorf("isFileQaGenerated() = true, expected false for other split settings")
+	}
+
+	qaPairs[1].FileSize = 90
+	if isFileQaGenerated(file, qaPairs, "Generated QA/0/0/0/") {
+		t.Errorf("isFileQaGenerated() = true, expected false when a pair was generated from an older version")
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/casibase/casibase/model"
+)
+
+const defaultRetrievalQueryCount = 3
+
+const (
+	multiQueryPrompt = "Generate %d different versions of the following question to retrieve relevant documents from a knowledge base. Use different words and perspectives, keep the original language. Output one question per line without numbering or other text.\n\nQuestion: %s"
+	hydePrompt       = "Write a short passage that answers the following question as a knowledge base document would, in the language of the question. Only output the passage.\n\nQuestion: %s"
+)
+
+var reQueryListPrefix = regexp.MustCompile(`^(\d+[.)、]|[-*•])\s*`)
+
+func getStoreRetrievalQueryCount(store *Store) int {
+	if store.RetrievalQueryCount <= 0 {
+		return defaultRetrievalQueryCount
+	}
+	return store.RetrievalQueryCount
+}
+
+// parseGeneratedQueries returns the question followed by at most n distinct paraphrases
+// from the lines of the answer.
+func parseGeneratedQueries(answer string, question string, n int) []string {
+	res := []string{question}
+	queryMap := map[string]bool{strings.ToLower(question): true}
+	for _, line := range strings.Split(answer, "\n") {
+		if len(res) > n {
+			break
+		}
+
+		query := strings.TrimSpace(reQueryListPrefix.ReplaceAllString(strings.TrimSpace(line), ""))
+		query = strings.Trim(query, "\"")
+		if query == "" || queryMap[strings.ToLower(query)] {
+			continue
+		}
+
+		queryMap[strings.ToLower(query)] = true
+		res = append(res, query)
+	}
+	return res
+}
+
+// GetRetrievalQueries returns the texts to embed for the knowledge search of a question by
+// the retrieval mode of the store: the question itself by default, the question and its
+// paraphrases for "Multi-query", or a hypothetical answer for "HyDE".
+func GetRetrievalQueries(store *Store, question string) ([]string, *model.ModelResult, error) {
+	if store.RetrievalMode != "Multi-query" && store.RetrievalMode != "HyDE" {
+		return []string{question}, nil, nil
+	}
+
+	modelProvider, err := store.GetModelProvider()
+	if err != nil {
+		return nil, nil, err
+	}
+	if modelProvider == nil {
+		return nil, nil, fmt.Errorf("The model provider for store: %s is not found", store.GetId())
+	}
+
+	modelProviderObj, err := modelProvider.GetModelProvider()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prompt := fmt.Sprintf(hydePrompt, question)
+	if store.RetrievalMode == "Multi-query" {
+		prompt = fmt.Sprintf(multiQueryPrompt, getStoreRetrievalQueryCount(store), question)
+	}
+
+	var writer MyWriter
+	modelResult, err := modelProviderObj.QueryText(prompt, &writer, []*model.RawMessage{}, "", []*model.RawMessage{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if store.RetrievalMode == "Multi-query" {
+		return parseGeneratedQueries(writer.String(), question, getStoreRetrievalQueryCount(store)), modelResult, nil
+	}
+
+	passage := strings.TrimSpace(writer.String())
+	if passage == "" {
+		return []string{question}, modelResult, nil
+	}
+	return []string{passage}, modelResult, nil
+}
+
+// mergeSearchResults deduplicates the results of several queries, keeping the best
+// scored result of each vector, and returns the n best vectors.
+func mergeSearchResults(results [][]Vector, n int) []Vector {
+	vectorMap := map[string]Vector{}
+	for _, vectors := range results {
+		for _, vector := range vectors {
+			v, ok := vectorMap[vector.Name]
+			if !ok || vector.Score > v.Score {
+				vectorMap[vector.Name] = vector
+			}
+		}
+	}
+
+	res := []Vector{}
+	for _, vector := range vectorMap {
+		res = append(res, vector)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score > res[j].Score
+		}
+		return res[i].Name < res[j].Name
+	})
+
+	if n < len(res) {
+		res = res[:n]
+	}
+	return res
+}
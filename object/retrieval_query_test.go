@@ -0,0 +1,41 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGeneratedQueries(t *testing.T) {
+	answer := "1. How do I reset my password?\n\n- how to change a forgotten password\n2) What is the way to recover account access?\nHow to reset password?\nOne more question"
+	queries := parseGeneratedQueries(answer, "How to reset password?", 3)
+	expected := []string{"How to reset password?", "How do I reset my password?", "how to change a forgotten password", "What is the way to recover account access?"}
+	if !reflect.DeepEqual(queries, expected) {
+		t.Errorf("parseGeneratedQueries() = %v, expected %v", queries, expected)
+	}
+}
+
+func TestMergeSearchResults(t *testing.T) {
+	results := [][]Vector{
+		{{Name: "vector_1", Score: 0.8}, {Name: "vector_2", Score: 0.6}},
+		{{Name: "vector_2", Score: 0.9}, {Name: "vector_3", Score: 0.5}},
+	}
+
+	vectors := mergeSearchResults(results, 2)
+	if len(vectors) != 2 || vectors[0].Name != "vector_2" || vectors[0].Score != 0.9 || vectors[1].Name != "vector_1" {
+		t.Errorf("mergeSearchResults() = %v, expected [vector_2 (0.9), vector_1]", vectors)
+	}
+}
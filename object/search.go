@@ -14,14 +14,22 @@
 
 package object
 
+import "fmt"
+
 type SearchProvider interface {
-	Search(embeddingProviderName string, qVector []float32) ([]Vector, error)
+	Search(embeddingProviderName string, qVector []float32, text string, filter *SearchFilter, n int) ([]Vector, error)
 }
 
-func GetSearchProvider(typ string, owner string) (SearchProvider, error) {
+func GetSearchProvider(typ string, owner string, store *Store) (SearchProvider, error) {
 	var p SearchProvider
 	var err error
-	if typ == "Default" {
+	if typ == "Hnsw" {
+		p, err = NewHnswSearchProvider(owner, store.Name, store.HnswM, store.HnswEf, store.Quantization)
+	} else if typ == "Hybrid" {
+		p, err = NewHybridSearchProvider(owner, store.Name, store.VectorWeight, store.KeywordWeight)
+	} else if typ == "Pgvector" {
+		p, err = NewPgvectorSearchProvider(owner, store.Name, store.PgvectorIndex)
+	} else {
 		p, err = NewDefaultSearchProvider(owner)
 	}
 
@@ -30,3 +38,64 @@ func GetSearchProvider(typ string, owner string) (SearchProvider, error) {
 	}
 	return p, nil
 }
+
+// getSearchIndexKey returns the key of the index of a store and embedding provider, the
+// names can't contain "/" as it separates the owner and name of an id.
+func getSearchIndexKey(storeName string, embeddingProviderName string) string {
+	return fmt.Sprintf("%s/%s", storeName, embeddingProviderName)
+}
+
+func addSearchIndexVector(vector *Vector) error {
+	err := addHnswVector(vector)
+	if err != nil {
+		return err
+	}
+
+	err = updatePgvectorForVector(vector, false)
+	if err != nil {
+		return err
+	}
+
+	return updateBm25IndexForVector(vector, false)
+}
+
+func updateSearchIndexVector(oldVector *Vector, vector *Vector) error {
+	if oldVector != nil && (oldVector.Name != vector.Name || oldVector.Store != vector.Store || oldVector.Provider != vector.Provider) {
+		err := deleteSearchIndexVector(oldVector)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := updateHnswVector(vector)
+	if err != nil {
+		return err
+	}
+
+	err = updatePgvectorForVector(vector, false)
+	if err != nil {
+		return err
+	}
+
+	return updateBm25IndexForVector(vector, false)
+}
+
+func deleteSearchIndexVector(vector *Vector) error {
+	err := deleteHnswVector(vector)
+	if err != nil {
+		return err
+	}
+
+	err = updatePgvectorForVector(vector, true)
+	if err != nil {
+		return err
+	}
+
+	return updateBm25IndexForVector(vector, true)
+}
+
+// refreshSearchIndexes re-syncs the search indexes of a store with its vectors after the store is refreshed.
+func refreshSearchIndexes(storeName string) error {
+	resetBm25Indexes(storeName)
+	return refreshHnswIndexes(storeName)
+}
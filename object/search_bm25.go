@@ -0,0 +1,264 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+var (
+	bm25IndexMap   = map[string]*Bm25Index{}
+	bm25IndexMutex sync.Mutex
+)
+
+type bm25Document struct {
+	vector     *Vector
+	termCounts map[string]int
+	length     int
+}
+
+// Bm25Index is an in-memory inverted index over the text of vectors.
+type Bm25Index struct {
+	store       string
+	documents   map[string]*bm25Document
+	postings    map[string]map[string]int
+	totalLength int
+	mutex       sync.RWMutex
+}
+
+type KeywordScore struct {
+	Vector *Vector
+	Score  float32
+}
+
+func newBm25Index(storeName string) *Bm25Index {
+	return &Bm25Index{
+		store:     storeName,
+		documents: map[string]*bm25Document{},
+		postings:  map[string]map[string]int{},
+	}
+}
+
+func isCjkRune(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+func isKeywordConnector(r rune) bool {
+	return r == '-' || r == '_' || r == '.' || r == '/'
+}
+
+// getKeywordTokens splits text into lowercase words, keeping identifiers like "ERR-1042"
+// or "v1.2.3" as a whole as well as their parts. CJK text has no spaces, so it is
+// indexed by unigrams and bigrams.
+func getKeywordTokens(text string) []string {
+	res := []string{}
+	word := []rune{}
+	var prevCjk rune
+
+	flush := func() {
+		s := strings.TrimRightFunc(string(word), isKeywordConnector)
+		word = word[:0]
+		if s == "" {
+			return
+		}
+
+		res = append(res, s)
+		if strings.IndexFunc(s, isKeywordConnector) != -1 {
+			for _, part := range strings.FieldsFunc(s, isKeywordConnector) {
+				res = append(res, part)
+			}
+		}
+	}
+
+	for _, r := range strings.ToLower(text) {
+		if isCjkRune(r) {
+			flush()
+			res = append(res, string(r))
+			if prevCjk != 0 {
+				res = append(res, string([]rune{prevCjk, r}))
+			}
+			prevCjk = r
+			continue
+		}
+
+		prevCjk = 0
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || (isKeywordConnector(r) && len(word) > 0) {
+			word = append(word, r)
+		} else {
+			flush()
+		}
+	}
+	flush()
+
+	return res
+}
+
+func (index *Bm25Index) add(vector *Vector) {
+	index.remove(vector.Name)
+
+	tokens := getKeywordTokens(vector.Text)
+	document := &bm25Document{
+		vector:     vector,
+		termCounts: map[string]int{},
+		length:     len(tokens),
+	}
+	for _, token := range tokens {
+		document.termCounts[token] += 1
+	}
+
+	for term, count := range document.termCounts {
+		if index.postings[term] == nil {
+			index.postings[term] = map[string]int{}
+		}
+		index.postings[term][vector.Name] = count
+	}
+
+	index.documents[vector.Name] = document
+	index.totalLength += document.length
+}
+
+func (index *Bm25Index) remove(name string) {
+	document, ok := index.documents[name]
+	if !ok {
+		return
+	}
+
+	for term := range document.termCounts {
+		delete(index.postings[term], name)
+		if len(index.postings[term]) == 0 {
+			delete(index.postings, term)
+		}
+	}
+
+	delete(index.documents, name)
+	index.totalLength -= document.length
+}
+
+func (index *Bm25Index) search(text string, n int) []KeywordScore {
+	res := []KeywordScore{}
+	if len(index.documents) == 0 {
+		return res
+	}
+
+	terms := map[string]bool{}
+	for _, token := range getKeywordTokens(text) {
+		terms[token] = true
+	}
+
+	documentCount := float64(len(index.documents))
+	averageLength := float64(index.totalLength) / documentCount
+	if averageLength == 0 {
+		averageLength = 1
+	}
+
+	scores := map[string]float64{}
+	for term := range terms {
+		postings := index.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+
+		df := float64(len(postings))
+		idf := math.Log(1 + (documentCount-df+0.5)/(df+0.5))
+		for name, count := range postings {
+			tf := float64(count)
+			length := float64(index.documents[name].length)
+			scores[name] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*length/averageLength))
+		}
+	}
+
+	for name, score := range scores {
+		res = append(res, KeywordScore{Vector: index.documents[name].vector, Score: float32(score)})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score > res[j].Score
+		}
+		return res[i].Vector.Name < res[j].Vector.Name
+	})
+
+	if n >= 0 && n < len(res) {
+		res = res[:n]
+	}
+	return res
+}
+
+// getBm25Index returns the keyword index of a store and embedding provider. Building it
+// only needs the cached texts, so it is kept in memory and built at the first search.
+func getBm25Index(storeName string, embeddingProviderName string, isCreated bool) (*Bm25Index, error) {
+	key := getSearchIndexKey(storeName, embeddingProviderName)
+
+	bm25IndexMutex.Lock()
+	defer bm25IndexMutex.Unlock()
+
+	index, ok := bm25IndexMap[key]
+	if ok || !isCreated {
+		return index, nil
+	}
+
+	vectors, err := getVectorCache(&Vector{Store: storeName, Provider: embeddingProviderName})
+	if err != nil {
+		return nil, err
+	}
+
+	index = newBm25Index(storeName)
+	for _, vector := range vectors {
+		index.add(vector)
+	}
+
+	bm25IndexMap[key] = index
+	return index, nil
+}
+
+func updateBm25IndexForVector(vector *Vector, isDeleted bool) error {
+	index, err := getBm25Index(vector.Store, vector.Provider, false)
+	if err != nil {
+		return err
+	}
+	if index == nil {
+		return nil
+	}
+
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	if isDeleted {
+		index.remove(vector.Name)
+	} else {
+		index.add(vector)
+	}
+	return nil
+}
+
+// resetBm25Indexes drops the keyword indexes of a store, they are rebuilt at the next search.
+func resetBm25Indexes(storeName string) {
+	bm25IndexMutex.Lock()
+	defer bm25IndexMutex.Unlock()
+
+	for key, index := range bm25IndexMap {
+		if index.store == storeName {
+			delete(bm25IndexMap, key)
+		}
+	}
+}
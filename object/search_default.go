@@ -22,27 +22,32 @@ func NewDefaultSearchProvider(owner string) (*DefaultSearchProvider, error) {
 	return &DefaultSearchProvider{owner: owner}, nil
 }
 
-func (p *DefaultSearchProvider) Search(embeddingProviderName string, qVector []float32) ([]Vector, error) {
-	vectors, err := getRelatedVectors(embeddingProviderName)
+func (p *DefaultSearchProvider) Search(embeddingProviderName string, qVector []float32, text string, filter *SearchFilter, n int) ([]Vector, error) {
+	vectors, err := getRelatedVectors(embeddingProviderName, filter)
 	if err != nil {
 		return nil, err
 	}
 
+	return searchNearestVectors(vectors, qVector, n)
+}
+
+func searchNearestVectors(vectors []*Vector, qVector []float32, n int) ([]Vector, error) {
 	var vectorData [][]float32
 	for _, candidate := range vectors {
 		vectorData = append(vectorData, candidate.Data)
 	}
 
-	similarities, err := getNearestVectors(qVector, vectorData, 5)
+	similarities, err := getNearestVectors(qVector, vectorData, n)
 	if err != nil {
 		return nil, err
 	}
 
 	res := []Vector{}
 	for _, similarity := range similarities {
-		vector := vectors[similarity.Index]
+		vector := *vectors[similarity.Index]
 		vector.Score = similarity.Similarity
-		res = append(res, *vector)
+		vector.Similarity = similarity.Similarity
+		res = append(res, vector)
 	}
 
 	return res, nil
@@ -0,0 +1,125 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SearchFilter restricts the vectors a search provider may return. Empty fields match
+// everything. Subject and the collected time range are matched against the Properties
+// of the vector's file in the store's PropertiesMap.
+type SearchFilter struct {
+	Store          string   `json:"store"`
+	FilePrefix     string   `json:"filePrefix"`
+	FileExtensions []string `json:"fileExtensions"`
+	Subject        string   `json:"subject"`
+	StartTime      string   `json:"startTime"`
+	EndTime        string   `json:"endTime"`
+
+	PropertiesMap map[string]*Properties `json:"-"`
+}
+
+// IsRestricted returns whether the filter needs more than the store to match vectors.
+func (filter *SearchFilter) IsRestricted() bool {
+	if filter == nil {
+		return false
+	}
+	return filter.FilePrefix != "" || len(filter.FileExtensions) != 0 || filter.Subject != "" || filter.StartTime != "" || filter.EndTime != ""
+}
+
+func (filter *SearchFilter) isMatched(vector *Vector) bool {
+	if filter == nil {
+		return true
+	}
+
+	if filter.Store != "" && vector.Store != filter.Store {
+		return false
+	}
+	if filter.FilePrefix != "" && !strings.HasPrefix(vector.File, filter.FilePrefix) {
+		return false
+	}
+	if len(filter.FileExtensions) != 0 && !isFileExtensionMatched(vector.File, filter.FileExtensions) {
+		return false
+	}
+
+	if filter.Subject == "" && filter.StartTime == "" && filter.EndTime == "" {
+		return true
+	}
+
+	properties, ok := filter.PropertiesMap[vector.File]
+	if !ok || properties == nil {
+		return false
+	}
+	if filter.Subject != "" && properties.Subject != filter.Subject {
+		return false
+	}
+	return isTimeInRange(properties.CollectedTime, filter.StartTime, filter.EndTime)
+}
+
+func (filter *SearchFilter) filterVectors(vectors []*Vector) []*Vector {
+	if filter == nil {
+		return vectors
+	}
+
+	res := []*Vector{}
+	for _, vector := range vectors {
+		if filter.isMatched(vector) {
+			res = append(res, vector)
+		}
+	}
+	return res
+}
+
+func isFileExtensionMatched(file string, extensions []string) bool {
+	ext := strings.ToLower(filepath.Ext(file))
+	for _, extension := range extensions {
+		extension = strings.ToLower(strings.TrimSpace(extension))
+		if extension != "" && !strings.HasPrefix(extension, ".") {
+			extension = "." + extension
+		}
+		if ext == extension {
+			return true
+		}
+	}
+	return false
+}
+
+func isTimeInRange(timeString string, startTime string, endTime string) bool {
+	if startTime == "" && endTime == "" {
+		return true
+	}
+
+	t, err := time.Parse(time.RFC3339, timeString)
+	if err != nil {
+		return false
+	}
+
+	if startTime != "" {
+		start, err := time.Parse(time.RFC3339, startTime)
+		if err == nil && t.Before(start) {
+			return false
+		}
+	}
+	if endTime != "" {
+		end, err := time.Parse(time.RFC3339, endTime)
+		if err == nil && t.After(end) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import "testing"
+
+func TestSearchFilter(t *testing.T) {
+	vectors := []*Vector{
+		{Name: "vector_1", Store: "store_a", File: "docs/guide.md"},
+		{Name: "vector_2", Store: "store_a", File: "docs/manual.PDF"},
+		{Name: "vector_3", Store: "store_a", File: "faq/faq.md"},
+		{Name: "vector_4", Store: "store_b", File: "docs/guide.md"},
+	}
+
+	propertiesMap := map[string]*Properties{
+		"docs/guide.md":   {Subject: "Guide", CollectedTime: "2024-03-01T00:00:00+08:00"},
+		"docs/manual.PDF": {Subject: "Manual", CollectedTime: "2024-01-01T00:00:00+08:00"},
+	}
+
+	tests := []struct {
+		name     string
+		filter   *SearchFilter
+		expected []string
+	}{
+		{"nil", nil, []string{"vector_1", "vector_2", "vector_3", "vector_4"}},
+		{"store", &SearchFilter{Store: "store_a"}, []string{"vector_1", "vector_2", "vector_3"}},
+		{"prefix", &SearchFilter{Store: "store_a", FilePrefix: "docs/"}, []string{"vector_1", "vector_2"}},
+		{"extension", &SearchFilter{Store: "store_a", FileExtensions: []string{"pdf"}}, []string{"vector_2"}},
+		{"subject", &SearchFilter{Store: "store_a", Subject: "Guide", PropertiesMap: propertiesMap}, []string{"vector_1"}},
+		{"time", &SearchFilter{Store: "store_a", StartTime: "2024-02-01T00:00:00Z", PropertiesMap: propertiesMap}, []string{"vector_1"}},
+	}
+
+	for _, test := range tests {
+		res := test.filter.filterVectors(vectors)
+		names := []string{}
+		for _, vector := range res {
+			names = append(names, vector.Name)
+		}
+		if len(names) != len(test.expected) {
+			t.Errorf("%s: filterVectors() = %v, expected %v", test.name, names, test.expected)
+			continue
+		}
+		for i := range names {
+			if names[i] != test.expected[i] {
+				t.Errorf("%s: filterVectors() = %v, expected %v", test.name, names, test.expected)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	defaultFreshnessHalfLife = 180
+	defaultFreshnessWeight   = 0.5
+)
+
+// reFileVersion matches the parts of a file name that tell versions of the same document
+// apart, like "v2", "rev 3", "1.0.2", "(1)" or dates like "2024-05-01".
+var reFileVersion = regexp.MustCompile(`(?i)\b(v|ver|version|rev|revision)[\s._-]?\d+(\.\d+)*\b|\b\d{4}[-_.]?\d{1,2}([-_.]?\d{1,2})?\b|\(\d+\)|\b\d+(\.\d+)+\b`)
+
+func getStoreFreshnessHalfLife(store *Store) float64 {
+	if store.FreshnessHalfLife <= 0 {
+		return defaultFreshnessHalfLife
+	}
+	return float64(store.FreshnessHalfLife)
+}
+
+func getStoreFreshnessWeight(store *Store) float64 {
+	if store.FreshnessWeight <= 0 || store.FreshnessWeight > 1 {
+		return defaultFreshnessWeight
+	}
+	return store.FreshnessWeight
+}
+
+// getVectorFreshness returns the time decay factor of a vector, 1 if no decay was applied.
+func getVectorFreshness(vector *Vector) float64 {
+	if vector.Freshness == 0 {
+		return 1
+	}
+	return float64(vector.Freshness)
+}
+
+// getFileTitle returns the path of a file without its extension and the version in its name,
+// so that the versions of a document in a folder have the same title. The folder is kept, as
+// the files of the same name in two folders are different documents.
+func getFileTitle(file string) string {
+	dir := strings.ToLower(path.Dir(file))
+	name := strings.ToLower(path.Base(file))
+	name = strings.TrimSuffix(name, path.Ext(name))
+	name = strings.ReplaceAll(name, "_", " ")
+
+	words := strings.FieldsFunc(reFileVersion.ReplaceAllString(name, " "), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) != 0 {
+		name = strings.Join(words, " ")
+	}
+
+	if dir == "." {
+		return name
+	}
+	return dir + "/" + name
+}
+
+func parseFileModifiedTime(modifiedTime string) (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339, modifiedTime)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// getFreshnessFactor returns (1 - weight) + weight * 0.5 ^ (age / halfLife), so a file
+// keeps at least 1 - weight of its score however old it is. Files of unknown age aren't decayed.
+func getFreshnessFactor(modifiedTime string, now time.Time, halfLife float64, weight float64) float64 {
+	t, ok := parseFileModifiedTime(modifiedTime)
+	if !ok {
+		return 1
+	}
+
+	age := now.Sub(t).Hours() / 24
+	if age < 0 {
+		age = 0
+	}
+	return (1 - weight) + weight*math.Pow(0.5, age/halfLife)
+}
+
+// applyTimeDecay multiplies the score of each vector by its freshness factor and orders the
+// vectors again. The rerank scores are kept as given, reranked vectors are ordered by the
+// rerank score times the freshness factor.
+func applyTimeDecay(vectors []Vector, now time.Time, halfLife float64, weight float64, isReranked bool) []Vector {
+	for i := range vectors {
+		factor := getFreshnessFactor(vectors[i].FileModifiedTime, now, halfLife, weight)
+		vectors[i].Freshness = float32(factor)
+		vectors[i].Score *= float32(factor)
+	}
+
+	sort.SliceStable(vectors, func(i, j int) bool {
+		if isReranked {
+			return float64(vectors[i].RerankScore)*getVectorFreshness(&vectors[i]) > float64(vectors[j].RerankScore)*getVectorFreshness(&vectors[j])
+		}
+		return vectors[i].Score > vectors[j].Score
+	})
+	return vectors
+}
+
+// getNewestFileTimes returns the modified time of the newest file of each title.
+func getNewestFileTimes(vectors []*Vector) map[string]time.Time {
+	res := map[string]time.Time{}
+	for _, vector := range vectors {
+		t, ok := parseFileModifiedTime(vector.FileModifiedTime)
+		if !ok {
+			continue
+		}
+
+		title := getFileTitle(vector.File)
+		if newestTime, ok := res[title]; !ok || t.After(newestTime) {
+			res[title] = t
+		}
+	}
+	return res
+}
+
+// filterNewestVersions drops the vectors of files superseded by a newer file with the same title.
+func filterNewestVersions(vectors []Vector, newestTimes map[string]time.Time) []Vector {
+	res := []Vector{}
+	for _, vector := range vectors {
+		t, ok := parseFileModifiedTime(vector.FileModifiedTime)
+		if ok {
+			newestTime, ok := newestTimes[getFileTitle(vector.File)]
+			if ok && t.Before(newestTime) {
+				continue
+			}
+		}
+		res = append(res, vector)
+	}
+	return res
+}
+
+// applyFreshness applies the store's freshness mode to the scored vectors: "Time decay"
+// lowers the scores of old files, "Newest version" only keeps the newest file of each title
+// among all the files of the store.
+func applyFreshness(store *Store, embeddingProviderName string, vectors []Vector, isReranked bool) ([]Vector, error) {
+	switch store.FreshnessMode {
+	case "Time decay":
+		return applyTimeDecay(vectors, time.Now(), getStoreFreshnessHalfLife(store), getStoreFreshnessWeight(store), isReranked), nil
+	case "Newest version":
+		storeVectors, err := getVectorCache(&Vector{Store: store.Name, Provider: embeddingProviderName})
+		if err != nil {
+			return nil, err
+		}
+		return filterNewestVersions(vectors, getNewestFileTimes(storeVectors)), nil
+	default:
+		return vectors, nil
+	}
+}
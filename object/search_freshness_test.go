@@ -0,0 +1,69 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetFileTitle(t *testing.T) {
+	tests := []struct {
+		file     string
+		expected string
+	}{
+		{"policies/Leave Policy v2.pdf", "policies/leave policy"},
+		{"Policies/Leave_Policy_2023-05.docx", "policies/leave policy"},
+		{"hr/policy.pdf", "hr/policy"},
+		{"it/policy.pdf", "it/policy"},
+		{"docs/v2/README.md", "docs/v2/readme"},
+		{"Leave Policy (1).pdf", "leave policy"},
+		{"Travel Policy rev3.1.md", "travel policy"},
+		{"2024.pdf", "2024"},
+	}
+
+	for _, test := range tests {
+		title := getFileTitle(test.file)
+		if title != test.expected {
+			t.Errorf("getFileTitle(%s) = %s, expected %s", test.file, title, test.expected)
+		}
+	}
+}
+
+func TestFreshness(t *testing.T) {
+	now := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	vectors := []Vector{
+		{Name: "vector_1", File: "Leave Policy v1.pdf", FileModifiedTime: "2023-07-02T00:00:00Z", Score: 1.0},
+		{Name: "vector_2", File: "Leave Policy v2.pdf", FileModifiedTime: "2024-06-01T00:00:00Z", Score: 0.8},
+		{Name: "vector_3", File: "Travel Policy.pdf", FileModifiedTime: "", Score: 0.7},
+	}
+
+	res := applyTimeDecay(append([]Vector{}, vectors...), now, 180, 0.5, false)
+	if res[0].Name != "vector_2" || res[1].Name != "vector_3" || res[2].Name != "vector_1" {
+		t.Fatalf("decayed order = [%s, %s, %s], expected [vector_2, vector_3, vector_1]", res[0].Name, res[1].Name, res[2].Name)
+	}
+	if res[1].Freshness != 1 || res[1].Score != 0.7 {
+		t.Errorf("a file of unknown age should not be decayed, got freshness: %f, score: %f", res[1].Freshness, res[1].Score)
+	}
+	if res[2].Freshness <= 0.5 || res[2].Freshness >= 0.75 {
+		t.Errorf("freshness of a file older than two half-lives = %f, expected between 0.5 and 0.75", res[2].Freshness)
+	}
+
+	storeVectors := []*Vector{&vectors[0], &vectors[1], &vectors[2], {File: "archive/Travel Policy 2025-01-01.pdf", FileModifiedTime: "invalid"}}
+	res = filterNewestVersions(vectors, getNewestFileTimes(storeVectors))
+	if len(res) != 2 || res[0].Name != "vector_2" || res[1].Name != "vector_3" {
+		t.Errorf("filterNewestVersions() returned %d vectors, expected [vector_2, vector_3]", len(res))
+	}
+}
@@ -0,0 +1,385 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/casibase/casibase/util"
+)
+
+var (
+	hnswIndexMap       = map[string]*HnswIndex{}
+	hnswIndexSavedTime = map[string]time.Time{}
+	hnswIndexMutexMap  = map[string]*sync.Mutex{}
+	hnswIndexMutex     sync.Mutex
+)
+
+type HnswSearchProvider struct {
+	owner        string
+	store        string
+	m            int
+	ef           int
+	quantization string
+}
+
+func NewHnswSearchProvider(owner string, store string, m int, ef int, quantization string) (*HnswSearchProvider, error) {
+	err := checkQuantization(quantization)
+	if err != nil {
+		return nil, err
+	}
+
+	if m <= 0 {
+		m = defaultHnswM
+	}
+	if ef <= 0 {
+		ef = defaultHnswEf
+	}
+
+	return &HnswSearchProvider{owner: owner, store: store, m: m, ef: ef, quantization: quantization}, nil
+}
+
+func (p *HnswSearchProvider) Search(embeddingProviderName string, qVector []float32, text string, filter *SearchFilter, n int) ([]Vector, error) {
+	index, err := getHnswIndex(p.store, embeddingProviderName, p.m, p.quantization, true)
+	if err != nil {
+		return nil, err
+	}
+
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+
+	if len(index.nameMap) == 0 {
+		return nil, fmt.Errorf("no knowledge vectors found")
+	}
+
+	// The graph knows nothing about the filter, so matching vectors are collected from
+	// a growing number of candidates until there are enough of them.
+	count := n
+	res := []Vector{}
+	for {
+		ef := p.ef
+		if ef < count {
+			ef = count
+		}
+
+		candidates, err := index.search(qVector, count, ef)
+		if err != nil {
+			return nil, err
+		}
+
+		vectorMap, err := getHnswCandidateVectors(index, candidates)
+		if err != nil {
+			return nil, err
+		}
+
+		res = []Vector{}
+		for _, candidate := range candidates {
+			vector, ok := vectorMap[index.Nodes[candidate.index].Name]
+			if !ok || !filter.isMatched(vector) {
+				continue
+			}
+
+			res = append(res, *vector)
+			res[len(res)-1].Score = candidate.similarity
+			res[len(res)-1].Similarity = candidate.similarity
+			if len(res) == n {
+				return res, nil
+			}
+		}
+
+		if !filter.IsRestricted() || count >= len(index.nameMap) {
+			break
+		}
+		count *= 4
+	}
+
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no knowledge vectors found")
+	}
+	return res, nil
+}
+
+// getHnswCandidateVectors reads the vectors of the candidates from the vector cache by their names.
+func getHnswCandidateVectors(index *HnswIndex, candidates []hnswCandidate) (map[string]*Vector, error) {
+	names := map[string]bool{}
+	for _, candidate := range candidates {
+		names[index.Nodes[candidate.index].Name] = true
+	}
+
+	vectors, err := getVectorCache(&Vector{Store: index.Store, Provider: index.Provider})
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]*Vector{}
+	for _, vector := range vectors {
+		if names[vector.Name] {
+			res[vector.Name] = vector
+		}
+	}
+	return res, nil
+}
+
+// getHnswIndexMutex returns the lock of a store and embedding provider's index, so building
+// or syncing one index doesn't block the others. hnswIndexMutex only guards the maps.
+func getHnswIndexMutex(key string) *sync.Mutex {
+	hnswIndexMutex.Lock()
+	defer hnswIndexMutex.Unlock()
+
+	mutex, ok := hnswIndexMutexMap[key]
+	if !ok {
+		mutex = &sync.Mutex{}
+		hnswIndexMutexMap[key] = mutex
+	}
+	return mutex
+}
+
+// getHnswIndex returns the in-memory index of a store and embedding provider, loading it
+// from disk when possible. A new index is only built when isCreated is true, and an index
+// built with a different M or quantization is rebuilt. m = 0 accepts any existing index.
+func getHnswIndex(storeName string, embeddingProviderName string, m int, quantization string, isCreated bool) (*HnswIndex, error) {
+	key := getSearchIndexKey(storeName, embeddingProviderName)
+
+	mutex := getHnswIndexMutex(key)
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	isMatched := func(index *HnswIndex) bool {
+		return m == 0 || (index.M == m && index.Quantization == quantization)
+	}
+
+	hnswIndexMutex.Lock()
+	index, ok := hnswIndexMap[key]
+	hnswIndexMutex.Unlock()
+	if ok && isMatched(index) {
+		return index, nil
+	}
+
+	path := util.GetHnswIndexPath(key)
+	if !ok && util.FileExist(path) {
+		var err error
+		index, err = loadHnswIndex(path)
+		if err != nil {
+			fmt.Printf("Failed to load HNSW index: [%s], rebuilding it: %s\n", path, err.Error())
+			index = nil
+		}
+	}
+
+	if index != nil && !isMatched(index) {
+		index = nil
+	}
+
+	if index == nil {
+		if !isCreated {
+			return nil, nil
+		}
+
+		index = newHnswIndex(m, defaultHnswEfConstruction, quantization)
+		index.Store = storeName
+		index.Provider = embeddingProviderName
+	}
+
+	err := syncHnswIndex(index, storeName, embeddingProviderName)
+	if err != nil {
+		return nil, err
+	}
+
+	hnswIndexMutex.Lock()
+	hnswIndexMap[key] = index
+	hnswIndexMutex.Unlock()
+	return index, saveHnswIndex(key, index, true)
+}
+
+// syncHnswIndex reconciles an index with the vector cache, so an index loaded
+// from disk only needs to apply the vectors changed since it was saved. A vector
+// whose data changed under the same name is found by its data hash and re-added.
+func syncHnswIndex(index *HnswIndex, storeName string, embeddingProviderName string) error {
+	vectors, err := getVectorCache(&Vector{Store: storeName, Provider: embeddingProviderName})
+	if err != nil {
+		return err
+	}
+
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	vectorMap := map[string]*Vector{}
+	for _, vector := range vectors {
+		vectorMap[vector.Name] = vector
+	}
+
+	for name := range index.nameMap {
+		vector, ok := vectorMap[name]
+		if !ok || len(vector.Data) != index.Dimension {
+			index.remove(name)
+		}
+	}
+
+	for _, vector := range vectors {
+		if i, ok := index.nameMap[vector.Name]; ok && index.Nodes[i].DataHash == getHnswDataHash(vector.Data) {
+			continue
+		}
+
+		err = index.add(vector.Name, vector.Data)
+		if err != nil {
+			return err
+		}
+	}
+
+	if index.needCompact() {
+		compactHnswIndex(index)
+	}
+	return nil
+}
+
+func compactHnswIndex(index *HnswIndex) {
+	nodes := index.Nodes
+
+	newIndex := newHnswIndex(index.M, index.EfConstruction, index.Quantization)
+	newIndex.Store = index.Store
+	newIndex.Provider = index.Provider
+	for _, node := range nodes {
+		if node.IsDeleted {
+			continue
+		}
+
+		// the node data is already normalized, so re-adding it is lossless
+		// apart from the rounding of a quantized index
+		_ = newIndex.add(node.Name, node.getData())
+		newIndex.Nodes[len(newIndex.Nodes)-1].DataHash = node.DataHash
+	}
+
+	index.Dimension = newIndex.Dimension
+	index.EntryPoint = newIndex.EntryPoint
+	index.MaxLevel = newIndex.MaxLevel
+	index.Nodes = newIndex.Nodes
+	index.DeletedCount = 0
+	index.nameMap = newIndex.nameMap
+	index.isDirty = true
+}
+
+// saveHnswIndex persists a dirty index, at most once a minute unless isForced is true.
+// The caller should hold the index's lock from getHnswIndexMutex.
+func saveHnswIndex(key string, index *HnswIndex, isForced bool) error {
+	hnswIndexMutex.Lock()
+	savedTime := hnswIndexSavedTime[key]
+	hnswIndexMutex.Unlock()
+	if !isForced && time.Since(savedTime) < time.Minute {
+		return nil
+	}
+
+	err := index.save(util.GetHnswIndexPath(key))
+	if err != nil {
+		return err
+	}
+
+	hnswIndexMutex.Lock()
+	hnswIndexSavedTime[key] = time.Now()
+	hnswIndexMutex.Unlock()
+	return nil
+}
+
+func updateHnswIndexForVector(vector *Vector, isDeleted bool) error {
+	index, err := getHnswIndex(vector.Store, vector.Provider, 0, "", false)
+	if err != nil {
+		return err
+	}
+	if index == nil {
+		return nil
+	}
+
+	index.mutex.Lock()
+	if isDeleted {
+		index.remove(vector.Name)
+	} else {
+		err = index.add(vector.Name, vector.Data)
+	}
+	if err == nil && index.needCompact() {
+		compactHnswIndex(index)
+	}
+	index.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	key := getSearchIndexKey(vector.Store, vector.Provider)
+	mutex := getHnswIndexMutex(key)
+	mutex.Lock()
+	defer mutex.Unlock()
+	return saveHnswIndex(key, index, false)
+}
+
+func addHnswVector(vector *Vector) error {
+	index, err := getHnswIndex(vector.Store, vector.Provider, 0, "", false)
+	if err != nil {
+		return err
+	}
+	if index == nil {
+		return nil
+	}
+
+	index.mutex.RLock()
+	_, ok := index.nameMap[vector.Name]
+	index.mutex.RUnlock()
+	if ok {
+		// already added when the index was loaded
+		return nil
+	}
+
+	return updateHnswIndexForVector(vector, false)
+}
+
+func updateHnswVector(vector *Vector) error {
+	return updateHnswIndexForVector(vector, false)
+}
+
+func deleteHnswVector(vector *Vector) error {
+	return updateHnswIndexForVector(vector, true)
+}
+
+// refreshHnswIndexes re-syncs and saves the loaded indexes of a store after its vector cache is reloaded.
+func refreshHnswIndexes(storeName string) error {
+	indexMap := map[string]*HnswIndex{}
+	hnswIndexMutex.Lock()
+	for key, index := range hnswIndexMap {
+		if index.Store == storeName {
+			indexMap[key] = index
+		}
+	}
+	hnswIndexMutex.Unlock()
+
+	for key, index := range indexMap {
+		err := refreshHnswIndex(key, index)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func refreshHnswIndex(key string, index *HnswIndex) error {
+	mutex := getHnswIndexMutex(key)
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	err := syncHnswIndex(index, index.Store, index.Provider)
+	if err != nil {
+		return err
+	}
+
+	return saveHnswIndex(key, index, true)
+}
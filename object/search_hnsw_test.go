@@ -0,0 +1,118 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+func getRandomVectors(count int, dimension int, seed int64) [][]float32 {
+	r := rand.New(rand.NewSource(seed))
+	res := [][]float32{}
+	for i := 0; i < count; i++ {
+		vec := make([]float32, dimension)
+		for j := range vec {
+			vec[j] = float32(r.NormFloat64())
+		}
+		res = append(res, vec)
+	}
+	return res
+}
+
+func getHnswRecall(index *HnswIndex, data [][]float32, queries [][]float32, n int, ef int) float64 {
+	hitCount := 0
+	for _, q := range queries {
+		expected, err := getNearestVectors(q, data, n)
+		if err != nil {
+			panic(err)
+		}
+
+		candidates, err := index.search(q, n, ef)
+		if err != nil {
+			panic(err)
+		}
+
+		names := map[string]bool{}
+		for _, candidate := range candidates {
+			names[index.Nodes[candidate.index].Name] = true
+		}
+		for _, similarity := range expected {
+			if names[fmt.Sprintf("vector_%d", similarity.Index)] {
+				hitCount += 1
+			}
+		}
+	}
+
+	return float64(hitCount) / float64(len(queries)*n)
+}
+
+func TestHnswIndex(t *testing.T) {
+	data := getRandomVectors(2000, 32, 1)
+	queries := getRandomVectors(50, 32, 2)
+
+	index := newHnswIndex(16, 200, "")
+	for i, vec := range data {
+		err := index.add(fmt.Sprintf("vector_%d", i), vec)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	recall := getHnswRecall(index, data, queries, 5, 64)
+	if recall < 0.9 {
+		t.Errorf("HNSW recall@5 = %f, expected >= 0.9", recall)
+	}
+
+	path := filepath.Join(t.TempDir(), "test.idx")
+	err := index.save(path)
+	if err != nil {
+		panic(err)
+	}
+
+	loadedIndex, err := loadHnswIndex(path)
+	if err != nil {
+		panic(err)
+	}
+	if loadedRecall := getHnswRecall(loadedIndex, data, queries, 5, 64); loadedRecall != recall {
+		t.Errorf("HNSW recall after loading = %f, expected %f", loadedRecall, recall)
+	}
+
+	for i := 0; i < len(data); i += 2 {
+		loadedIndex.remove(fmt.Sprintf("vector_%d", i))
+	}
+	candidates, err := loadedIndex.search(queries[0], 5, 64)
+	if err != nil {
+		panic(err)
+	}
+	if len(candidates) != 5 {
+		t.Errorf("HNSW search after removal returned %d results, expected 5", len(candidates))
+	}
+	for _, candidate := range candidates {
+		if candidate.index%2 == 0 {
+			t.Errorf("HNSW search returned the removed vector: %s", loadedIndex.Nodes[candidate.index].Name)
+		}
+	}
+
+	compactHnswIndex(loadedIndex)
+	for i := 1; i < len(data); i += 2 {
+		node := loadedIndex.Nodes[loadedIndex.nameMap[fmt.Sprintf("vector_%d", i)]]
+		if node.DataHash != getHnswDataHash(data[i]) {
+			t.Errorf("HNSW data hash of vector_%d after compaction = %s, expected %s", i, node.DataHash, getHnswDataHash(data[i]))
+		}
+	}
+}
@@ -0,0 +1,436 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/casibase/casibase/util"
+)
+
+const (
+	defaultHnswM              = 16
+	defaultHnswEf             = 64
+	defaultHnswEfConstruction = 200
+)
+
+type HnswNode struct {
+	Name      string
+	Data      []float32
+	Quantized *QuantizedVector
+	Level     int
+	Neighbors [][]int
+	IsDeleted bool
+	DataHash  string
+}
+
+// HnswIndex is a Hierarchical Navigable Small World graph over normalized
+// vectors, so the inner product of two nodes equals their cosine similarity.
+// With a quantization, the nodes only keep a quantized copy of their data. The
+// nodes only keep the names of their vectors, which are read from the vector cache.
+type HnswIndex struct {
+	Store          string
+	Provider       string
+	M              int
+	EfConstruction int
+	Quantization   string
+	Dimension      int
+	EntryPoint     int
+	MaxLevel       int
+	Nodes          []*HnswNode
+	DeletedCount   int
+
+	nameMap map[string]int
+	rand    *rand.Rand
+	isDirty bool
+	mutex   sync.RWMutex
+}
+
+func newHnswIndex(m int, efConstruction int, quantization string) *HnswIndex {
+	if m <= 1 {
+		m = defaultHnswM
+	}
+	if efConstruction < m {
+		efConstruction = defaultHnswEfConstruction
+	}
+
+	return &HnswIndex{
+		M:              m,
+		EfConstruction: efConstruction,
+		Quantization:   quantization,
+		EntryPoint:     -1,
+		MaxLevel:       -1,
+		Nodes:          []*HnswNode{},
+		nameMap:        map[string]int{},
+		rand:           rand.New(rand.NewSource(int64(m))),
+	}
+}
+
+func normalizeVector(vec []float32) []float32 {
+	res := make([]float32, len(vec))
+	vecNorm := norm(vec)
+	if vecNorm == 0 {
+		return res
+	}
+
+	for i, val := range vec {
+		res[i] = val / vecNorm
+	}
+	return res
+}
+
+func (index *HnswIndex) getMaxNeighborCount(level int) int {
+	if level == 0 {
+		return index.M * 2
+	}
+	return index.M
+}
+
+func (index *HnswIndex) getRandomLevel() int {
+	mL := 1 / math.Log(float64(index.M))
+	return int(math.Floor(-math.Log(1-index.rand.Float64()) * mL))
+}
+
+func (index *HnswIndex) similarity(q []float32, i int) float32 {
+	node := index.Nodes[i]
+	if node.Quantized != nil {
+		return node.Quantized.dot(q)
+	}
+	return dot(q, node.Data)
+}
+
+// getHnswDataHash returns the hash of a vector's original data, used to find the
+// vectors changed since the index was saved.
+func getHnswDataHash(data []float32) string {
+	hash := fnv.New64a()
+	buf := make([]byte, 4)
+	for _, value := range data {
+		binary.LittleEndian.PutUint32(buf, math.Float32bits(value))
+		hash.Write(buf)
+	}
+	return fmt.Sprintf("%x", hash.Sum64())
+}
+
+func (node *HnswNode) getData() []float32 {
+	if node.Quantized != nil {
+		return node.Quantized.dequantize()
+	}
+	return node.Data
+}
+
+type hnswCandidate struct {
+	index      int
+	similarity float32
+}
+
+// hnswHeap is a heap of candidates, ordered by the most similar first if isMax is true.
+type hnswHeap struct {
+	items []hnswCandidate
+	isMax bool
+}
+
+func (h *hnswHeap) Len() int { return len(h.items) }
+
+func (h *hnswHeap) Less(i, j int) bool {
+	if h.isMax {
+		return h.items[i].similarity > h.items[j].similarity
+	}
+	return h.items[i].similarity < h.items[j].similarity
+}
+
+func (h *hnswHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *hnswHeap) Push(x interface{}) { h.items = append(h.items, x.(hnswCandidate)) }
+
+func (h *hnswHeap) Pop() interface{} {
+	n := len(h.items)
+	item := h.items[n-1]
+	h.items = h.items[:n-1]
+	return item
+}
+
+func (h *hnswHeap) peek() hnswCandidate {
+	return h.items[0]
+}
+
+func (index *HnswIndex) greedySearch(q []float32, entryPoint int, level int) int {
+	current := entryPoint
+	currentSimilarity := index.similarity(q, current)
+	for changed := true; changed; {
+		changed = false
+		for _, neighbor := range index.Nodes[current].Neighbors[level] {
+			similarity := index.similarity(q, neighbor)
+			if similarity > currentSimilarity {
+				current = neighbor
+				currentSimilarity = similarity
+				changed = true
+			}
+		}
+	}
+	return current
+}
+
+// searchLayer returns up to ef nearest candidates of q on a level, sorted by similarity descending.
+func (index *HnswIndex) searchLayer(q []float32, entryPoint int, ef int, level int) []hnswCandidate {
+	visited := map[int]bool{entryPoint: true}
+	entry := hnswCandidate{index: entryPoint, similarity: index.similarity(q, entryPoint)}
+	candidates := &hnswHeap{items: []hnswCandidate{entry}, isMax: true}
+	results := &hnswHeap{items: []hnswCandidate{entry}, isMax: false}
+
+	for candidates.Len() > 0 {
+		candidate := heap.Pop(candidates).(hnswCandidate)
+		if results.Len() >= ef && candidate.similarity < results.peek().similarity {
+			break
+		}
+
+		node := index.Nodes[candidate.index]
+		if level >= len(node.Neighbors) {
+			continue
+		}
+
+		for _, neighbor := range node.Neighbors[level] {
+			if visited[neighbor] {
+				continue
+			}
+			visited[neighbor] = true
+
+			similarity := index.similarity(q, neighbor)
+			if results.Len() < ef || similarity > results.peek().similarity {
+				heap.Push(candidates, hnswCandidate{index: neighbor, similarity: similarity})
+				heap.Push(results, hnswCandidate{index: neighbor, similarity: similarity})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	res := results.items
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].similarity > res[j].similarity
+	})
+	return res
+}
+
+func (index *HnswIndex) shrinkNeighbors(i int, level int) {
+	node := index.Nodes[i]
+	maxCount := index.getMaxNeighborCount(level)
+	if len(node.Neighbors[level]) <= maxCount {
+		return
+	}
+
+	data := node.getData()
+	candidates := []hnswCandidate{}
+	for _, neighbor := range node.Neighbors[level] {
+		candidates = append(candidates, hnswCandidate{index: neighbor, similarity: index.similarity(data, neighbor)})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].similarity > candidates[j].similarity
+	})
+
+	neighbors := []int{}
+	for _, candidate := range candidates[:maxCount] {
+		neighbors = append(neighbors, candidate.index)
+	}
+	node.Neighbors[level] = neighbors
+}
+
+func (index *HnswIndex) add(name string, data []float32) error {
+	if index.Dimension == 0 {
+		index.Dimension = len(data)
+	}
+	if len(data) != index.Dimension {
+		return fmt.Errorf("The vector: [%s]'s length: [%d] should equal to the index's dimension: [%d]", name, len(data), index.Dimension)
+	}
+
+	if _, ok := index.nameMap[name]; ok {
+		index.remove(name)
+	}
+
+	level := index.getRandomLevel()
+	normalizedData := normalizeVector(data)
+	node := &HnswNode{
+		Name:      name,
+		Level:     level,
+		Neighbors: make([][]int, level+1),
+		DataHash:  getHnswDataHash(data),
+	}
+	if index.Quantization != "" {
+		node.Quantized = quantizeVector(normalizedData, index.Quantization)
+	} else {
+		node.Data = normalizedData
+	}
+	i := len(index.Nodes)
+	index.Nodes = append(index.Nodes, node)
+	index.nameMap[name] = i
+	index.isDirty = true
+
+	if index.EntryPoint == -1 {
+		index.EntryPoint = i
+		index.MaxLevel = level
+		return nil
+	}
+
+	entryPoint := index.EntryPoint
+	for l := index.MaxLevel; l > level; l-- {
+		entryPoint = index.greedySearch(normalizedData, entryPoint, l)
+	}
+
+	topLevel := level
+	if topLevel > index.MaxLevel {
+		topLevel = index.MaxLevel
+	}
+	for l := topLevel; l >= 0; l-- {
+		candidates := index.searchLayer(normalizedData, entryPoint, index.EfConstruction, l)
+
+		maxCount := index.getMaxNeighborCount(l)
+		neighbors := []int{}
+		for _, candidate := range candidates {
+			if len(neighbors) >= maxCount {
+				break
+			}
+			neighbors = append(neighbors, candidate.index)
+		}
+		node.Neighbors[l] = neighbors
+
+		for _, neighbor := range neighbors {
+			index.Nodes[neighbor].Neighbors[l] = append(index.Nodes[neighbor].Neighbors[l], i)
+			index.shrinkNeighbors(neighbor, l)
+		}
+
+		entryPoint = candidates[0].index
+	}
+
+	if level > index.MaxLevel {
+		index.EntryPoint = i
+		index.MaxLevel = level
+	}
+	return nil
+}
+
+// remove marks a node as deleted. The node stays in the graph so the links
+// through it remain navigable, and is dropped when the index is rebuilt.
+func (index *HnswIndex) remove(name string) {
+	i, ok := index.nameMap[name]
+	if !ok {
+		return
+	}
+
+	index.Nodes[i].IsDeleted = true
+	delete(index.nameMap, name)
+	index.DeletedCount += 1
+	index.isDirty = true
+}
+
+func (index *HnswIndex) needCompact() bool {
+	return index.DeletedCount > 100 && index.DeletedCount*2 > len(index.Nodes)
+}
+
+func (index *HnswIndex) search(target []float32, n int, ef int) ([]hnswCandidate, error) {
+	if index.EntryPoint == -1 {
+		return []hnswCandidate{}, nil
+	}
+	if len(target) != index.Dimension {
+		return nil, fmt.Errorf("The target vector's length: [%d] should equal to the index's dimension: [%d]", len(target), index.Dimension)
+	}
+
+	if ef < n {
+		ef = n
+	}
+	// over-fetch so that tombstoned nodes don't starve the results
+	ef += index.DeletedCount * ef / (len(index.Nodes) - index.DeletedCount + 1)
+
+	q := normalizeVector(target)
+	entryPoint := index.EntryPoint
+	for l := index.MaxLevel; l > 0; l-- {
+		entryPoint = index.greedySearch(q, entryPoint, l)
+	}
+
+	res := []hnswCandidate{}
+	for _, candidate := range index.searchLayer(q, entryPoint, ef, 0) {
+		if index.Nodes[candidate.index].IsDeleted {
+			continue
+		}
+
+		res = append(res, candidate)
+		if len(res) >= n {
+			break
+		}
+	}
+	return res, nil
+}
+
+func (index *HnswIndex) save(path string) error {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	if !index.isDirty {
+		return nil
+	}
+
+	util.EnsureFileFolderExists(path)
+
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	err = gob.NewEncoder(file).Encode(index)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	index.isDirty = false
+	return os.Rename(tmpPath, path)
+}
+
+func loadHnswIndex(path string) (*HnswIndex, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	index := &HnswIndex{}
+	err = gob.NewDecoder(file).Decode(index)
+	if err != nil {
+		return nil, err
+	}
+
+	index.nameMap = map[string]int{}
+	for i, node := range index.Nodes {
+		if !node.IsDeleted {
+			index.nameMap[node.Name] = i
+		}
+	}
+	index.rand = rand.New(rand.NewSource(int64(len(index.Nodes))))
+	return index, nil
+}
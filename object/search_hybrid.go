@@ -0,0 +1,139 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	hybridCandidateCount = 20
+	rrfK                 = 60
+)
+
+// HybridSearchProvider combines cosine similarity over the embeddings with BM25
+// over the texts, and merges both rankings with weighted reciprocal rank fusion.
+type HybridSearchProvider struct {
+	owner         string
+	store         string
+	vectorWeight  float64
+	keywordWeight float64
+}
+
+func NewHybridSearchProvider(owner string, store string, vectorWeight float64, keywordWeight float64) (*HybridSearchProvider, error) {
+	if vectorWeight == 0 && keywordWeight == 0 {
+		vectorWeight = 1
+		keywordWeight = 1
+	}
+
+	return &HybridSearchProvider{owner: owner, store: store, vectorWeight: vectorWeight, keywordWeight: keywordWeight}, nil
+}
+
+func (p *HybridSearchProvider) Search(embeddingProviderName string, qVector []float32, text string, filter *SearchFilter, n int) ([]Vector, error) {
+	vectors, err := getVectorCache(&Vector{Store: p.store, Provider: embeddingProviderName})
+	if err != nil {
+		return nil, err
+	}
+
+	vectors = filter.filterVectors(vectors)
+	if len(vectors) == 0 {
+		return nil, fmt.Errorf("no knowledge vectors found")
+	}
+
+	candidateCount := hybridCandidateCount
+	if candidateCount < n {
+		candidateCount = n
+	}
+
+	vectorResults, err := searchNearestVectors(vectors, qVector, candidateCount)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := getBm25Index(p.store, embeddingProviderName, true)
+	if err != nil {
+		return nil, err
+	}
+
+	index.mutex.RLock()
+	keywordResults := index.search(text, -1)
+	index.mutex.RUnlock()
+
+	keywordResults = filterKeywordResults(keywordResults, filter, candidateCount)
+
+	res := fuseSearchResults(vectorResults, keywordResults, qVector, p.vectorWeight, p.keywordWeight, n)
+	return res, nil
+}
+
+func filterKeywordResults(keywordResults []KeywordScore, filter *SearchFilter, n int) []KeywordScore {
+	res := []KeywordScore{}
+	for _, keywordResult := range keywordResults {
+		if len(res) == n {
+			break
+		}
+		if filter.isMatched(keywordResult.Vector) {
+			res = append(res, keywordResult)
+		}
+	}
+	return res
+}
+
+func getSimilarity(qVector []float32, data []float32) float32 {
+	if len(qVector) != len(data) {
+		return 0
+	}
+	return cosineSimilarity(qVector, data, norm(qVector))
+}
+
+// fuseSearchResults ranks the vectors by weight / (rrfK + rank) summed over both result lists.
+func fuseSearchResults(vectorResults []Vector, keywordResults []KeywordScore, qVector []float32, vectorWeight float64, keywordWeight float64, n int) []Vector {
+	vectorMap := map[string]*Vector{}
+	for i := range vectorResults {
+		vector := &vectorResults[i]
+		vector.Score = float32(vectorWeight / float64(rrfK+i+1))
+		vectorMap[vector.Name] = vector
+	}
+
+	for i, keywordResult := range keywordResults {
+		vector, ok := vectorMap[keywordResult.Vector.Name]
+		if !ok {
+			v := *keywordResult.Vector
+			v.Score = 0
+			v.Similarity = getSimilarity(qVector, v.Data)
+			vector = &v
+			vectorMap[vector.Name] = vector
+		}
+
+		vector.KeywordScore = keywordResult.Score
+		vector.Score += float32(keywordWeight / float64(rrfK+i+1))
+	}
+
+	res := []Vector{}
+	for _, vector := range vectorMap {
+		res = append(res, *vector)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score > res[j].Score
+		}
+		return res[i].Similarity > res[j].Similarity
+	})
+
+	if n < len(res) {
+		res = res[:n]
+	}
+	return res
+}
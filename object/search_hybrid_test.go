@@ -0,0 +1,60 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetKeywordTokens(t *testing.T) {
+	tokens := getKeywordTokens("Error ERR-1042 in v1.2, 数据库")
+	expected := []string{"error", "err-1042", "err", "1042", "in", "v1.2", "v1", "2", "数", "据", "数据", "库", "据库"}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("getKeywordTokens() = %v, expected %v", tokens, expected)
+	}
+}
+
+func TestHybridSearch(t *testing.T) {
+	vectors := []*Vector{
+		{Name: "vector_1", Text: "How to reset the password of an account", Data: []float32{1, 0, 0}},
+		{Name: "vector_2", Text: "The device reports ERR-1042 when the disk is full", Data: []float32{0, 1, 0}},
+		{Name: "vector_3", Text: "Account settings and profile", Data: []float32{0.9, 0.1, 0}},
+	}
+
+	index := newBm25Index("store")
+	for _, vector := range vectors {
+		index.add(vector)
+	}
+
+	keywordResults := index.search("what does err-1042 mean", 3)
+	if len(keywordResults) != 1 || keywordResults[0].Vector.Name != "vector_2" {
+		t.Fatalf("BM25 search returned %v, expected only vector_2", keywordResults)
+	}
+
+	qVector := []float32{1, 0.05, 0}
+	vectorResults, err := searchNearestVectors(vectors, qVector, 3)
+	if err != nil {
+		panic(err)
+	}
+
+	res := fuseSearchResults(vectorResults, keywordResults, qVector, 1, 1, 2)
+	if len(res) != 2 || res[0].Name != "vector_2" || res[1].Name != "vector_1" {
+		t.Errorf("fused results = [%s, %s], expected [vector_2, vector_1]", res[0].Name, res[1].Name)
+	}
+	if res[0].KeywordScore == 0 || res[0].Similarity == 0 {
+		t.Errorf("fused result should keep both scores, got keyword score: %f, similarity: %f", res[0].KeywordScore, res[0].Similarity)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+const (
+	defaultTopK        = 5
+	defaultMmrLambda   = 0.5
+	defaultRerankCount = 20
+	mmrCandidateFactor = 4
+)
+
+func getStoreTopK(store *Store) int {
+	if store.TopK <= 0 {
+		return defaultTopK
+	}
+	return store.TopK
+}
+
+// getStoreCandidateCount returns how many vectors to fetch from the search provider,
+// MMR and reranking need more candidates than they finally keep to have something to
+// choose from.
+func getStoreCandidateCount(store *Store) int {
+	topK := getStoreTopK(store)
+	res := topK
+	if store.UseMmr {
+		res = topK * mmrCandidateFactor
+	}
+
+	if store.RerankProvider != "" {
+		rerankCount := store.RerankCount
+		if rerankCount <= 0 {
+			rerankCount = defaultRerankCount
+		}
+		if res < rerankCount {
+			res = rerankCount
+		}
+	}
+	return res
+}
+
+func filterVectorsBySimilarity(vectors []Vector, minSimilarity float64) []Vector {
+	if minSimilarity <= 0 {
+		return vectors
+	}
+
+	res := []Vector{}
+	for _, vector := range vectors {
+		if float64(vector.Similarity) >= minSimilarity {
+			res = append(res, vector)
+		}
+	}
+	return res
+}
+
+// selectMmrVectors picks n vectors by Maximal Marginal Relevance: each step takes the
+// candidate maximizing lambda * relevance to the query - (1 - lambda) * the highest
+// similarity to an already selected vector. The relevance is the rerank score for
+// reranked vectors and the similarity otherwise, times the freshness of a time decay.
+func selectMmrVectors(vectors []Vector, lambda float64, n int, isReranked bool) []Vector {
+	if lambda <= 0 || lambda > 1 {
+		lambda = defaultMmrLambda
+	}
+
+	res := []Vector{}
+	selected := make([]bool, len(vectors))
+	redundancies := make([]float64, len(vectors))
+	for len(res) < n && len(res) < len(vectors) {
+		best := -1
+		bestScore := 0.0
+		for i, vector := range vectors {
+			if selected[i] {
+				continue
+			}
+
+			relevance := float64(vector.Similarity)
+			if isReranked {
+				relevance = float64(vector.RerankScore)
+			}
+			relevance *= getVectorFreshness(&vector)
+
+			score := lambda*relevance - (1-lambda)*redundancies[i]
+			if best == -1 || score > bestScore {
+				best = i
+				bestScore = score
+			}
+		}
+
+		selected[best] = true
+		res = append(res, vectors[best])
+
+		for i, vector := range vectors {
+			if selected[i] {
+				continue
+			}
+
+			similarity := float64(getSimilarity(vectors[best].Data, vector.Data))
+			if similarity > redundancies[i] {
+				redundancies[i] = similarity
+			}
+		}
+	}
+
+	return res
+}
+
+// selectTopVectors applies the store's MMR and top-K settings to the vectors ordered
+// by the search provider or the rerank provider.
+func selectTopVectors(store *Store, vectors []Vector, isReranked bool) []Vector {
+	topK := getStoreTopK(store)
+	if store.UseMmr {
+		return selectMmrVectors(vectors, store.MmrLambda, topK, isReranked)
+	}
+
+	if topK < len(vectors) {
+		vectors = vectors[:topK]
+	}
+	return vectors
+}
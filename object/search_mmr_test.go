@@ -0,0 +1,77 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"testing"
+
+	"github.com/casibase/casibase/rerank"
+)
+
+func TestSelectTopVectors(t *testing.T) {
+	vectors := []Vector{
+		{Name: "vector_1", Similarity: 0.9, Data: []float32{1, 0, 0}},
+		{Name: "vector_2", Similarity: 0.89, Data: []float32{0.99, 0.01, 0}},
+		{Name: "vector_3", Similarity: 0.8, Data: []float32{0, 1, 0}},
+		{Name: "vector_4", Similarity: 0.3, Data: []float32{0, 0, 1}},
+	}
+
+	res := selectTopVectors(&Store{TopK: 2}, vectors, false)
+	if len(res) != 2 || res[0].Name != "vector_1" || res[1].Name != "vector_2" {
+		t.Errorf("top-K results = %v, expected [vector_1, vector_2]", getVectorNames(res))
+	}
+
+	res = selectTopVectors(&Store{TopK: 2, UseMmr: true}, vectors, false)
+	if len(res) != 2 || res[0].Name != "vector_1" || res[1].Name != "vector_3" {
+		t.Errorf("MMR results = %v, expected [vector_1, vector_3]", getVectorNames(res))
+	}
+
+	res = filterVectorsBySimilarity(vectors, 0.5)
+	if len(res) != 3 {
+		t.Errorf("results above the minimum similarity = %v, expected 3 vectors", getVectorNames(res))
+	}
+
+	res = filterVectorsBySimilarity(vectors, 0.95)
+	if len(res) != 0 {
+		t.Errorf("results above the minimum similarity = %v, expected none", getVectorNames(res))
+	}
+}
+
+func TestApplyRerankScores(t *testing.T) {
+	vectors := []Vector{
+		{Name: "vector_1", Similarity: 0.9, Data: []float32{1, 0, 0}},
+		{Name: "vector_2", Similarity: 0.89, Data: []float32{0.99, 0.01, 0}},
+		{Name: "vector_3", Similarity: 0.8, Data: []float32{0, 1, 0}},
+	}
+	scores := []rerank.RerankScore{{Index: 2, Score: 0.95}, {Index: 1, Score: 0.7}, {Index: 0, Score: 0.6}}
+
+	res := applyRerankScores(vectors, scores)
+	if len(res) != 3 || res[0].Name != "vector_3" || res[0].RerankScore != 0.95 {
+		t.Errorf("reranked results = %v, expected vector_3 first", getVectorNames(res))
+	}
+
+	res = selectTopVectors(&Store{TopK: 2, UseMmr: true}, res, true)
+	if len(res) != 2 || res[0].Name != "vector_3" || res[1].Name != "vector_2" {
+		t.Errorf("MMR results after reranking = %v, expected [vector_3, vector_2]", getVectorNames(res))
+	}
+}
+
+func getVectorNames(vectors []Vector) []string {
+	res := []string{}
+	for _, vector := range vectors {
+		res = append(res, vector.Name)
+	}
+	return res
+}
@@ -0,0 +1,469 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/casibase/casibase/util"
+)
+
+const (
+	pgvectorTableName = "pgvector_embedding"
+
+	// pgvector can't build HNSW or IVFFlat indexes over more dimensions than this,
+	// larger embeddings are searched by exact scan.
+	pgvectorMaxIndexDimension = 2000
+
+	// a missing table is looked up again after this interval, as the migration or another
+	// server may create it meanwhile
+	pgvectorTableCheckInterval = time.Minute
+)
+
+var (
+	isPgvectorTableReady     bool
+	isPgvectorTableMissing   bool
+	pgvectorTableCheckedTime time.Time
+	pgvectorIndexMap         = map[string]bool{}
+	pgvectorMutex            sync.Mutex
+)
+
+// PgvectorSearchProvider keeps a copy of the embeddings in a pgvector column of the
+// PostgreSQL database, and runs the top-K query with the store and file filters in SQL.
+type PgvectorSearchProvider struct {
+	owner     string
+	store     string
+	indexType string
+}
+
+func NewPgvectorSearchProvider(owner string, store string, indexType string) (*PgvectorSearchProvider, error) {
+	if indexType == "" {
+		indexType = "Hnsw"
+	}
+	if indexType != "Hnsw" && indexType != "IvfFlat" {
+		return nil, fmt.Errorf("The pgvector index type: %s is not supported, it should be Hnsw or IvfFlat", indexType)
+	}
+
+	err := ensurePgvectorTable()
+	if err != nil {
+		return nil, err
+	}
+
+	return &PgvectorSearchProvider{owner: owner, store: store, indexType: indexType}, nil
+}
+
+func (p *PgvectorSearchProvider) Search(embeddingProviderName string, qVector []float32, text string, filter *SearchFilter, n int) ([]Vector, error) {
+	err := ensurePgvectorIndex(p.indexType, len(qVector))
+	if err != nil {
+		return nil, err
+	}
+
+	// the subject and time of a file are not in the pgvector table, so they are matched
+	// after the query, on the next pages of candidates until n vectors are matched
+	isFiltered := filter != nil && (filter.Subject != "" || filter.StartTime != "" || filter.EndTime != "")
+	limit := n
+	if isFiltered {
+		limit = n * 4
+	}
+
+	res := []Vector{}
+	for offset := 0; ; offset += limit {
+		query, args := getPgvectorQuery(p.store, embeddingProviderName, qVector, filter, limit, offset)
+		rows, err := adapter.engine.QueryString(append([]interface{}{query}, args...)...)
+		if err != nil {
+			return nil, err
+		}
+
+		vectors, err := getPgvectorResults(rows, filter, n-len(res))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, vectors...)
+
+		if !isFiltered || len(res) == n || len(rows) < limit {
+			break
+		}
+	}
+
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no knowledge vectors found")
+	}
+	return res, nil
+}
+
+// getPgvectorResults returns at most n vectors of the rows of a pgvector query, which match the filter.
+func getPgvectorResults(rows []map[string]string, filter *SearchFilter, n int) ([]Vector, error) {
+	res := []Vector{}
+	if len(rows) == 0 {
+		return res, nil
+	}
+
+	names := []string{}
+	for _, row := range rows {
+		names = append(names, row["name"])
+	}
+
+	vectors := []*Vector{}
+	err := adapter.engine.In("name", names).Find(&vectors)
+	if err != nil {
+		return nil, err
+	}
+
+	vectorMap := map[string]*Vector{}
+	for _, vector := range vectors {
+		vectorMap[vector.GetId()] = vector
+	}
+
+	for _, row := range rows {
+		vector, ok := vectorMap[fmt.Sprintf("%s/%s", row["owner"], row["name"])]
+		if !ok || !filter.isMatched(vector) {
+			continue
+		}
+
+		distance, err := strconv.ParseFloat(row["distance"], 64)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, *vector)
+		res[len(res)-1].Score = float32(1 - distance)
+		res[len(res)-1].Similarity = float32(1 - distance)
+		if len(res) == n {
+			break
+		}
+	}
+	return res, nil
+}
+
+// getPgvectorQuery returns the cosine distance query of the top-K vectors. The embedding
+// column has no fixed dimension, so it is cast to the dimension of the query vector to
+// match the partial index of that dimension.
+func getPgvectorQuery(storeName string, embeddingProviderName string, qVector []float32, filter *SearchFilter, n int, offset int) (string, []interface{}) {
+	dimension := len(qVector)
+	distance := fmt.Sprintf("embedding::vector(%d) <=> ?::vector(%d)", dimension, dimension)
+	qText := getPgvectorText(qVector)
+
+	conditions := []string{fmt.Sprintf("dimension = %d", dimension), "store = ?", "provider = ?"}
+	args := []interface{}{qText, storeName, embeddingProviderName}
+
+	if filter != nil && filter.FilePrefix != "" {
+		conditions = append(conditions, "file LIKE ?")
+		args = append(args, escapeLikePattern(filter.FilePrefix)+"%")
+	}
+
+	if filter != nil && len(filter.FileExtensions) != 0 {
+		extensionConditions := []string{}
+		for _, extension := range filter.FileExtensions {
+			extension = strings.ToLower(strings.TrimSpace(extension))
+			if extension == "" {
+				continue
+			}
+			if !strings.HasPrefix(extension, ".") {
+				extension = "." + extension
+			}
+
+			extensionConditions = append(extensionConditions, "lower(file) LIKE ?")
+			args = append(args, "%"+escapeLikePattern(extension))
+		}
+		if len(extensionConditions) != 0 {
+			conditions = append(conditions, fmt.Sprintf("(%s)", strings.Join(extensionConditions, " OR ")))
+		}
+	}
+
+	query := fmt.Sprintf("SELECT owner, name, %s AS distance FROM %s WHERE %s ORDER BY %s LIMIT %d",
+		distance, pgvectorTableName, strings.Join(conditions, " AND "), distance, n)
+	if offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", offset)
+	}
+	args = append(args, qText)
+	return query, args
+}
+
+func escapeLikePattern(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "%", "\\%")
+	return strings.ReplaceAll(s, "_", "\\_")
+}
+
+func getPgvectorText(data []float32) string {
+	var sb strings.Builder
+	sb.WriteString("[")
+	for i, f := range data {
+		if i != 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(strconv.FormatFloat(float64(f), 'f', -1, 32))
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+func ensurePgvectorTable() error {
+	if adapter.driverName != "postgres" {
+		return fmt.Errorf("The pgvector search provider requires the postgres database driver, current driver: %s", adapter.driverName)
+	}
+
+	pgvectorMutex.Lock()
+	defer pgvectorMutex.Unlock()
+
+	if isPgvectorTableReady {
+		return nil
+	}
+
+	existed, err := adapter.engine.IsTableExist(pgvectorTableName)
+	if err != nil {
+		return err
+	}
+
+	queries := []string{
+		"CREATE EXTENSION IF NOT EXISTS vector",
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (owner varchar(100) NOT NULL, name varchar(100) NOT NULL, store varchar(100), provider varchar(100), file varchar(100), dimension integer, embedding vector, PRIMARY KEY (owner, name))", pgvectorTableName),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_store_provider ON %s (store, provider)", pgvectorTableName, pgvectorTableName),
+	}
+	for _, query := range queries {
+		_, err = adapter.engine.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the vectors added before are not in a new table yet, and the vector changes made
+	// while the table was missing are not in a table created by the migration or another server
+	if !existed {
+		err = copyVectorsToPgvector()
+	} else if isPgvectorTableMissing {
+		err = syncVectorsToPgvector()
+	}
+	if err != nil {
+		return err
+	}
+
+	isPgvectorTableReady = true
+	isPgvectorTableMissing = false
+	return nil
+}
+
+// isPgvectorEnabled returns whether the pgvector table is in use, the vector changes are
+// only copied into it after a store searched with pgvector or the migration created it.
+// A missing table is looked up again at most every pgvectorTableCheckInterval, and is
+// caught up with the vector changes made meanwhile when it is found.
+func isPgvectorEnabled() (bool, error) {
+	if adapter.driverName != "postgres" {
+		return false, nil
+	}
+
+	pgvectorMutex.Lock()
+	defer pgvectorMutex.Unlock()
+
+	if isPgvectorTableReady {
+		return true, nil
+	}
+	if time.Since(pgvectorTableCheckedTime) < pgvectorTableCheckInterval {
+		return false, nil
+	}
+
+	existed, err := adapter.engine.IsTableExist(pgvectorTableName)
+	if err != nil {
+		return false, err
+	}
+	pgvectorTableCheckedTime = time.Now()
+
+	if !existed {
+		isPgvectorTableMissing = true
+		return false, nil
+	}
+
+	if isPgvectorTableMissing {
+		err = syncVectorsToPgvector()
+		if err != nil {
+			return false, err
+		}
+	}
+
+	isPgvectorTableReady = true
+	isPgvectorTableMissing = false
+	return true, nil
+}
+
+func ensurePgvectorIndex(indexType string, dimension int) error {
+	if dimension == 0 || dimension > pgvectorMaxIndexDimension {
+		return nil
+	}
+
+	key := fmt.Sprintf("%s_%d", strings.ToLower(indexType), dimension)
+
+	pgvectorMutex.Lock()
+	defer pgvectorMutex.Unlock()
+
+	if pgvectorIndexMap[key] {
+		return nil
+	}
+
+	var query string
+	if indexType == "IvfFlat" {
+		count, err := adapter.engine.Table(pgvectorTableName).Where("dimension = ?", dimension).Count()
+		if err != nil {
+			return err
+		}
+
+		// the recommended lists for up to 1M rows
+		lists := count / 1000
+		if lists < 1 {
+			lists = 1
+		}
+		query = fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_%s ON %s USING ivfflat ((embedding::vector(%d)) vector_cosine_ops) WITH (lists = %d) WHERE dimension = %d",
+			pgvectorTableName, key, pgvectorTableName, dimension, lists, dimension)
+	} else {
+		query = fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_%s ON %s USING hnsw ((embedding::vector(%d)) vector_cosine_ops) WHERE dimension = %d",
+			pgvectorTableName, key, pgvectorTableName, dimension, dimension)
+	}
+
+	_, err := adapter.engine.Exec(query)
+	if err != nil {
+		return err
+	}
+
+	pgvectorIndexMap[key] = true
+	return nil
+}
+
+func upsertPgvector(vector *Vector) error {
+	if len(vector.Data) == 0 {
+		return deletePgvector(vector)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (owner, name, store, provider, file, dimension, embedding) VALUES (?, ?, ?, ?, ?, ?, ?::vector) "+
+		"ON CONFLICT (owner, name) DO UPDATE SET store = EXCLUDED.store, provider = EXCLUDED.provider, file = EXCLUDED.file, dimension = EXCLUDED.dimension, embedding = EXCLUDED.embedding", pgvectorTableName)
+	_, err := adapter.engine.Exec(query, vector.Owner, vector.Name, vector.Store, vector.Provider, vector.File, len(vector.Data), getPgvectorText(vector.Data))
+	return err
+}
+
+func deletePgvector(vector *Vector) error {
+	_, err := adapter.engine.Exec(fmt.Sprintf("DELETE FROM %s WHERE owner = ? AND name = ?", pgvectorTableName), vector.Owner, vector.Name)
+	return err
+}
+
+func updatePgvectorForVector(vector *Vector, isDeleted bool) error {
+	enabled, err := isPgvectorEnabled()
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return nil
+	}
+
+	if isDeleted {
+		return deletePgvector(vector)
+	}
+	return upsertPgvector(vector)
+}
+
+// MigrateVectorsToPgvector copies all the vectors into the pgvector table and builds the
+// indexes of the stores searched with pgvector. It can be run again to catch up with the
+// vectors changed while the table was not in use.
+func MigrateVectorsToPgvector() error {
+	enabled, err := isPgvectorEnabled()
+	if err != nil {
+		return err
+	}
+
+	if enabled {
+		err = copyVectorsToPgvector()
+	} else {
+		// a new table is filled when it is created
+		err = ensurePgvectorTable()
+	}
+	if err != nil {
+		return err
+	}
+
+	return buildPgvectorIndexes()
+}
+
+func copyVectorsToPgvector() error {
+	count, err := adapter.engine.Count(&Vector{})
+	if err != nil {
+		return err
+	}
+
+	batchSize := 1000
+	for offset := 0; int64(offset) < count; offset += batchSize {
+		vectors := []*Vector{}
+		err = adapter.engine.Asc("owner").Asc("name").Limit(batchSize, offset).Find(&vectors)
+		if err != nil {
+			return err
+		}
+
+		for _, vector := range vectors {
+			err = upsertPgvector(vector)
+			if err != nil {
+				return err
+			}
+		}
+
+		fmt.Printf("[%d/%d] Copying vectors to pgvector\n", offset+len(vectors), count)
+	}
+
+	return nil
+}
+
+// syncVectorsToPgvector catches an existing table up with the vectors, including the
+// vectors deleted since it was filled.
+func syncVectorsToPgvector() error {
+	err := copyVectorsToPgvector()
+	if err != nil {
+		return err
+	}
+
+	_, err = adapter.engine.Exec(fmt.Sprintf("DELETE FROM %s p WHERE NOT EXISTS (SELECT 1 FROM vector v WHERE v.owner = p.owner AND v.name = p.name)", pgvectorTableName))
+	return err
+}
+
+// buildPgvectorIndexes builds the indexes now instead of at the first question, IVFFlat
+// needs the copied rows to pick its lists.
+func buildPgvectorIndexes() error {
+	stores, err := GetGlobalStores()
+	if err != nil {
+		return err
+	}
+
+	for _, store := range stores {
+		if store.SearchProvider != "Pgvector" {
+			continue
+		}
+
+		rows, err := adapter.engine.QueryString(fmt.Sprintf("SELECT DISTINCT dimension FROM %s WHERE store = ?", pgvectorTableName), store.Name)
+		if err != nil {
+			return err
+		}
+
+		indexType := store.PgvectorIndex
+		if indexType == "" {
+			indexType = "Hnsw"
+		}
+		for _, row := range rows {
+			err = ensurePgvectorIndex(indexType, util.ParseInt(row["dimension"]))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !skipCi
+// +build !skipCi
+
+package object
+
+import (
+	"testing"
+)
+
+// TestPgvectorSearch needs driverName = postgres in conf/app.conf and a database with
+// the pgvector extension, e.g. the pgvector/pgvector:pg16 container.
+func TestPgvectorSearch(t *testing.T) {
+	InitConfig()
+	if adapter.driverName != "postgres" {
+		t.Skip("the database driver is not postgres")
+	}
+
+	vectors := []*Vector{
+		{Owner: "admin", Name: "pgvector_test_1", Store: "pgvector_test", Provider: "provider", File: "a.md", Data: []float32{1, 0, 0}},
+		{Owner: "admin", Name: "pgvector_test_2", Store: "pgvector_test", Provider: "provider", File: "b.pdf", Data: []float32{0, 1, 0}},
+		{Owner: "admin", Name: "pgvector_test_3", Store: "pgvector_test", Provider: "provider", File: "c.md", Data: []float32{0.9, 0.1, 0}},
+	}
+	for _, vector := range vectors {
+		_, err := AddVector(vector)
+		if err != nil {
+			panic(err)
+		}
+	}
+	defer func() {
+		for _, vector := range vectors {
+			_, err := DeleteVector(vector)
+			if err != nil {
+				panic(err)
+			}
+		}
+	}()
+
+	err := MigrateVectorsToPgvector()
+	if err != nil {
+		panic(err)
+	}
+
+	p, err := NewPgvectorSearchProvider("admin", "pgvector_test", "Hnsw")
+	if err != nil {
+		panic(err)
+	}
+
+	res, err := p.Search("provider", []float32{1, 0.05, 0}, "", &SearchFilter{FileExtensions: []string{"md"}}, 2)
+	if err != nil {
+		panic(err)
+	}
+	if len(res) != 2 || res[0].Name != "pgvector_test_1" || res[1].Name != "pgvector_test_3" {
+		t.Errorf("Search() returned %d vectors, expected pgvector_test_1 and pgvector_test_3", len(res))
+	}
+}
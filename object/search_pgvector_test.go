@@ -0,0 +1,43 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetPgvectorQuery(t *testing.T) {
+	filter := &SearchFilter{FilePrefix: "docs/100%_", FileExtensions: []string{"PDF", ".md"}}
+	query, args := getPgvectorQuery("store", "provider", []float32{0.5, -1, 0.25}, filter, 5, 0)
+
+	expectedQuery := "SELECT owner, name, embedding::vector(3) <=> ?::vector(3) AS distance FROM pgvector_embedding " +
+		"WHERE dimension = 3 AND store = ? AND provider = ? AND file LIKE ? AND (lower(file) LIKE ? OR lower(file) LIKE ?) " +
+		"ORDER BY embedding::vector(3) <=> ?::vector(3) LIMIT 5"
+	if query != expectedQuery {
+		t.Errorf("getPgvectorQuery() query = %s, expected %s", query, expectedQuery)
+	}
+
+	expectedArgs := []interface{}{"[0.5,-1,0.25]", "store", "provider", "docs/100\\%\\_%", "%.pdf", "%.md", "[0.5,-1,0.25]"}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("getPgvectorQuery() args = %v, expected %v", args, expectedArgs)
+	}
+
+	query, _ = getPgvectorQuery("store", "provider", []float32{0.5, -1, 0.25}, nil, 20, 40)
+	if !strings.HasSuffix(query, " LIMIT 20 OFFSET 40") {
+		t.Errorf("getPgvectorQuery() query = %s, expected the page of 20 rows at offset 40", query)
+	}
+}
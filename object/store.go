@@ -15,9 +15,11 @@
 package object
 
 import (
+	"context"
 	"fmt"
 	"time"
 
+	"github.com/casibase/casibase/split"
 	"github.com/casibase/casibase/storage"
 	"github.com/casibase/casibase/util"
 	"xorm.io/core"
@@ -63,23 +65,53 @@ type Store struct {
 	SplitProvider     string `xorm:"varchar(100)" json:"splitProvider"`
 	ModelProvider     string `xorm:"varchar(100)" json:"modelProvider"`
 	EmbeddingProvider string `xorm:"varchar(100)" json:"embeddingProvider"`
+	SearchProvider    string `xorm:"varchar(100)" json:"searchProvider"`
+	RerankProvider    string `xorm:"varchar(100)" json:"rerankProvider"`
 
 	ModelProviders     []string             `xorm:"mediumtext" json:"modelProviders" xorm:"json"`
 	EmbeddingProviders []string             `xorm:"mediumtext" json:"embeddingProviders" xorm:"json"`
 	ModelUsageMap      map[string]UsageInfo `xorm:"mediumtext" json:"modelUsageMap" xorm:"json"`
 	EmbeddingUsageMap  map[string]UsageInfo `xorm:"mediumtext" json:"embeddingUsageMap" xorm:"json"`
 
-	MemoryLimit     int      `json:"memoryLimit"`
-	Frequency       int      `json:"frequency"`
-	LimitMinutes    int      `json:"limitMinutes"`
-	SuggestionCount int      `json:"suggestionCount"`
-	Welcome         string   `xorm:"varchar(100)" json:"welcome"`
-	Prompt          string   `xorm:"mediumtext" json:"prompt"`
-	Prompts         []Prompt `xorm:"mediumtext" json:"prompts"`
-	ThemeColor      string   `xorm:"varchar(100)" json:"themeColor"`
-	Avatar          string   `xorm:"varchar(200)" json:"avatar"`
-	Title           string   `xorm:"varchar(100)" json:"title"`
-	CanSelectStore  bool     `json:"canSelectStore"`
+	MemoryLimit          int      `json:"memoryLimit"`
+	Frequency            int      `json:"frequency"`
+	LimitMinutes         int      `json:"limitMinutes"`
+	SuggestionCount      int      `json:"suggestionCount"`
+	Welcome              string   `xorm:"varchar(100)" json:"welcome"`
+	Prompt               string   `xorm:"mediumtext" json:"prompt"`
+	Prompts              []Prompt `xorm:"mediumtext" json:"prompts"`
+	ThemeColor           string   `xorm:"varchar(100)" json:"themeColor"`
+	Avatar               string   `xorm:"varchar(200)" json:"avatar"`
+	Title                string   `xorm:"varchar(100)" json:"title"`
+	CanSelectStore       bool     `json:"canSelectStore"`
+	HnswM                int      `json:"hnswM"`
+	HnswEf               int      `json:"hnswEf"`
+	Quantization         string   `xorm:"varchar(100)" json:"quantization"`
+	PgvectorIndex        string   `xorm:"varchar(100)" json:"pgvectorIndex"`
+	VectorWeight         float64  `json:"vectorWeight"`
+	KeywordWeight        float64  `json:"keywordWeight"`
+	TopK                 int      `json:"topK"`
+	MinSimilarity        float64  `json:"minSimilarity"`
+	UseMmr               bool     `json:"useMmr"`
+	MmrLambda            float64  `json:"mmrLambda"`
+	RerankCount          int      `json:"rerankCount"`
+	EmbeddingBatchSize   int      `json:"embeddingBatchSize"`
+	EmbeddingConcurrency int      `json:"embeddingConcurrency"`
+	CondenseQuestion     bool     `json:"condenseQuestion"`
+	RetrievalMode        string   `xorm:"varchar(100)" json:"retrievalMode"`
+	RetrievalQueryCount  int      `json:"retrievalQueryCount"`
+	EnableAnswerCache    bool     `json:"enableAnswerCache"`
+	AnswerCacheThreshold float64  `json:"answerCacheThreshold"`
+	FreshnessMode        string   `xorm:"varchar(100)" json:"freshnessMode"`
+	FreshnessHalfLife    int      `json:"freshnessHalfLife"`
+	FreshnessWeight      float64  `json:"freshnessWeight"`
+	ChunkSize            int      `json:"chunkSize"`
+	ChunkOverlap         int      `json:"chunkOverlap"`
+	MinChunkSize         int      `json:"minChunkSize"`
+	Tokenizer            string   `xorm:"varchar(100)" json:"tokenizer"`
+
+	KnowledgeFallback string `xorm:"varchar(100)" json:"knowledgeFallback"`
+	FallbackReply     string `xorm:"mediumtext" json:"fallbackReply"`
 
 	FileTree      *File                  `xorm:"mediumtext" json:"fileTree"`
 	PropertiesMap map[string]*Properties `xorm:"mediumtext" json:"propertiesMap"`
@@ -221,6 +253,16 @@ func (store *Store) GetModelProvider() (*Provider, error) {
 	return GetProvider(providerId)
 }
 
+func (store *Store) GetSplitConfig() *split.SplitConfig {
+	return &split.SplitConfig{
+		Type:         store.SplitProvider,
+		ChunkSize:    store.ChunkSize,
+		ChunkOverlap: store.ChunkOverlap,
+		MinChunkSize: store.MinChunkSize,
+		Tokenizer:    store.Tokenizer,
+	}
+}
+
 func (store *Store) GetEmbeddingProvider() (*Provider, error) {
 	if store.EmbeddingProvider == "" {
 		return GetDefaultEmbeddingProvider()
@@ -230,40 +272,85 @@ func (store *Store) GetEmbeddingProvider() (*Provider, error) {
 	return GetProvider(providerId)
 }
 
-func RefreshStoreVectors(store *Store) (bool, error) {
+func RefreshStoreVectors(store *Store) (*VectorRefreshSummary, error) {
+	return refreshStoreVectors(context.Background(), store, nil)
+}
+
+func refreshStoreVectors(ctx context.Context, store *Store, job *Job) (*VectorRefreshSummary, error) {
+	embeddingProvider, err := store.GetEmbeddingProvider()
+	if err != nil {
+		return nil, err
+	}
+	if embeddingProvider == nil {
+		return nil, fmt.Errorf("The embedding provider for store: %s is not found", store.GetId())
+	}
+
+	return refreshStoreVectorsWithProvider(ctx, store, embeddingProvider, job)
+}
+
+// refreshStoreVectorsWithProvider embeds the files of a store with the given embedding
+// provider, which is not the provider of the store during a migration.
+func refreshStoreVectorsWithProvider(ctx context.Context, store *Store, embeddingProvider *Provider, job *Job) (*VectorRefreshSummary, error) {
 	storageProviderObj, err := store.GetStorageProviderObj()
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	modelProvider, err := store.GetModelProvider()
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	if modelProvider == nil {
-		return false, fmt.Errorf("The model provider for store: %s is not found", store.GetId())
+		return nil, fmt.Errorf("The model provider for store: %s is not found", store.GetId())
 	}
 
-	embeddingProvider, err := store.GetEmbeddingProvider()
+	embeddingProviderObj, err := embeddingProvider.GetEmbeddingProvider()
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	if embeddingProvider == nil {
-		return false, fmt.Errorf("The embedding provider for store: %s is not found", store.GetId())
+
+	if store.SearchProvider == "Pgvector" {
+		// create the pgvector table before adding the vectors, so they are copied into it
+		err = ensurePgvectorTable()
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	embeddingProviderObj, err := embeddingProvider.GetEmbeddingProvider()
+	batchSize := store.EmbeddingBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultEmbeddingBatchSize
+	}
+
+	concurrency := store.EmbeddingConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultEmbeddingConcurrency
+	}
+
+	limiter := getProviderRateLimiter(embeddingProvider)
+	splitConfig := store.GetSplitConfig()
+	if splitConfig.Type == "Generated QA" {
+		modelProviderObj, err := modelProvider.GetModelProvider()
+		if err != nil {
+			return nil, err
+		}
+		splitConfig.GenerateText = getSplitTextGenerator(modelProviderObj)
+	}
+
+	summary, err := addVectorsForStore(ctx, storageProviderObj, embeddingProviderObj, "", store.Name, splitConfig, embeddingProvider, modelProvider.SubType, limiter, batchSize, concurrency, job)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	limit := 100000
-	if embeddingProvider.Type == "OpenAI" {
-		limit = 3
+	if store.SearchProvider == "Hnsw" {
+		// build the index now instead of at the first question
+		_, err = getHnswIndex(store.Name, embeddingProvider.Name, store.HnswM, store.Quantization, true)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	ok, err := addVectorsForStore(storageProviderObj, embeddingProviderObj, "", store.Name, store.SplitProvider, embeddingProvider.Name, modelProvider.SubType, limit)
-	return ok, err
+	return summary, nil
 }
 
 func GetStoreCount(field, value string) (int64, error) {
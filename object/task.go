@@ -44,6 +44,10 @@ type Task struct {
 	Example     string   `xorm:"varchar(200)" json:"example"`
 	Labels      []string `xorm:"mediumtext" json:"labels"`
 	Log         string   `xorm:"mediumtext" json:"log"`
+
+	// the store and golden questions of an "Evaluation" task
+	Store     string            `xorm:"varchar(100)" json:"store"`
+	GoldenSet []*GoldenQuestion `xorm:"mediumtext" json:"goldenSet"`
 }
 
 func GetMaskedTask(task *Task, isMaskEnabled bool) *Task {
@@ -0,0 +1,503 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/casibase/casibase/embedding"
+	"github.com/casibase/casibase/model"
+	"github.com/casibase/casibase/util"
+	"xorm.io/core"
+)
+
+// GoldenQuestion is a question of an evaluation task with its expected retrieval and
+// answer. ExpectedChunk is a piece of text of the expected chunk rather than its index,
+// so that the same golden set can be used to compare different splits.
+type GoldenQuestion struct {
+	Question       string `json:"question"`
+	ExpectedFile   string `json:"expectedFile"`
+	ExpectedChunk  string `json:"expectedChunk"`
+	ExpectedAnswer string `json:"expectedAnswer"`
+}
+
+// ParseGoldenSet reads the golden questions from a JSON array or from a CSV file with a
+// header row of "question", "expectedFile", "expectedChunk" and "expectedAnswer" columns.
+func ParseGoldenSet(fileName string, data []byte) ([]*GoldenQuestion, error) {
+	res := []*GoldenQuestion{}
+	if strings.ToLower(filepath.Ext(fileName)) == ".json" {
+		err := json.Unmarshal(data, &res)
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return res, nil
+	}
+
+	columnMap := map[string]int{}
+	for i, column := range rows[0] {
+		columnMap[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	if _, ok := columnMap["question"]; !ok {
+		return nil, fmt.Errorf("The golden set: %s should have a \"question\" column", fileName)
+	}
+
+	getColumn := func(row []string, column string) string {
+		i, ok := columnMap[strings.ToLower(column)]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	for _, row := range rows[1:] {
+		goldenQuestion := &GoldenQuestion{
+			Question:       getColumn(row, "question"),
+			ExpectedFile:   getColumn(row, "expectedFile"),
+			ExpectedChunk:  getColumn(row, "expectedChunk"),
+			ExpectedAnswer: getColumn(row, "expectedAnswer"),
+		}
+		if goldenQuestion.Question != "" {
+			res = append(res, goldenQuestion)
+		}
+	}
+	return res, nil
+}
+
+type EvaluationResult struct {
+	Question    string   `json:"question"`
+	Rank        int      `json:"rank"`
+	Files       []string `json:"files"`
+	Answer      string   `json:"answer"`
+	AnswerScore float64  `json:"answerScore"`
+	Error       string   `json:"error"`
+}
+
+// EvaluationConfig is the configuration of the store at the time of an evaluation run.
+type EvaluationConfig struct {
+	SplitProvider     string  `json:"splitProvider"`
+	ChunkSize         int     `json:"chunkSize"`
+	ChunkOverlap      int     `json:"chunkOverlap"`
+	MinChunkSize      int     `json:"minChunkSize"`
+	Tokenizer         string  `json:"tokenizer"`
+	EmbeddingProvider string  `json:"embeddingProvider"`
+	SearchProvider    string  `json:"searchProvider"`
+	RerankProvider    string  `json:"rerankProvider"`
+	ModelProvider     string  `json:"modelProvider"`
+	RetrievalMode     string  `json:"retrievalMode"`
+	TopK              int     `json:"topK"`
+	MinSimilarity     float64 `json:"minSimilarity"`
+	UseMmr            bool    `json:"useMmr"`
+}
+
+// Evaluation is a run of the golden set of a task against its store. The runs are kept,
+// so that the scores of different store configurations can be compared.
+type Evaluation struct {
+	Owner       string `xorm:"varchar(100) notnull pk" json:"owner"`
+	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
+	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
+
+	Task         string            `xorm:"varchar(100) index" json:"task"`
+	Store        string            `xorm:"varchar(100)" json:"store"`
+	State        string            `xorm:"varchar(100)" json:"state"`
+	FinishedTime string            `xorm:"varchar(100)" json:"finishedTime"`
+	Config       *EvaluationConfig `xorm:"mediumtext" json:"config"`
+
+	K             int                 `json:"k"`
+	QuestionCount int                 `json:"questionCount"`
+	RecallAtK     float64             `json:"recallAtK"`
+	Mrr           float64             `json:"mrr"`
+	AnswerMatch   float64             `json:"answerMatch"`
+	Results       []*EvaluationResult `xorm:"mediumtext" json:"results"`
+	TokenCount    int                 `json:"tokenCount"`
+	Price         float64             `json:"price"`
+	Currency      string              `xorm:"varchar(100)" json:"currency"`
+	Error         string              `xorm:"mediumtext" json:"error"`
+}
+
+func GetEvaluations(owner string, task string) ([]*Evaluation, error) {
+	evaluations := []*Evaluation{}
+	err := adapter.engine.Desc("created_time").Find(&evaluations, &Evaluation{Owner: owner, Task: task})
+	if err != nil {
+		return evaluations, err
+	}
+
+	return evaluations, nil
+}
+
+func getEvaluation(owner string, name string) (*Evaluation, error) {
+	evaluation := Evaluation{Owner: owner, Name: name}
+	existed, err := adapter.engine.Get(&evaluation)
+	if err != nil {
+		return &evaluation, err
+	}
+
+	if existed {
+		return &evaluation, nil
+	} else {
+		return nil, nil
+	}
+}
+
+func GetEvaluation(id string) (*Evaluation, error) {
+	owner, name := util.GetOwnerAndNameFromId(id)
+	return getEvaluation(owner, name)
+}
+
+func AddEvaluation(evaluation *Evaluation) (bool, error) {
+	affected, err := adapter.engine.Insert(evaluation)
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func DeleteEvaluation(evaluation *Evaluation) (bool, error) {
+	affected, err := adapter.engine.ID(core.PK{evaluation.Owner, evaluation.Name}).Delete(&Evaluation{})
+	if err != nil {
+		return false, err
+	}
+
+	return affected != 0, nil
+}
+
+func (evaluation *Evaluation) GetId() string {
+	return fmt.Sprintf("%s/%s", evaluation.Owner, evaluation.Name)
+}
+
+func (evaluation *Evaluation) save() error {
+	_, err := adapter.engine.ID(core.PK{evaluation.Owner, evaluation.Name}).AllCols().Update(evaluation)
+	return err
+}
+
+func normalizeEvaluationText(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// isGoldenVectorMatched returns whether the vector is the expected one of the golden question.
+// The expected file may be given by its full key or only by its name.
+func isGoldenVectorMatched(goldenQuestion *GoldenQuestion, vector *Vector) bool {
+	if goldenQuestion.ExpectedFile != "" && vector.File != goldenQuestion.ExpectedFile && !strings.HasSuffix(vector.File, "/"+goldenQuestion.ExpectedFile) {
+		return false
+	}
+	if goldenQuestion.ExpectedChunk != "" && !strings.Contains(normalizeEvaluationText(vector.Text), normalizeEvaluationText(goldenQuestion.ExpectedChunk)) {
+		return false
+	}
+	return true
+}
+
+// getGoldenRank returns the 1-based rank of the first expected vector, or 0 if it was not retrieved.
+func getGoldenRank(goldenQuestion *GoldenQuestion, vectors []*Vector) int {
+	if goldenQuestion.ExpectedFile == "" && goldenQuestion.ExpectedChunk == "" {
+		return 0
+	}
+
+	for i, vector := range vectors {
+		if isGoldenVectorMatched(goldenQuestion, vector) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// getAnswerMatchScore returns the F1 score of the keyword tokens of the answer against the expected answer.
+func getAnswerMatchScore(answer string, expectedAnswer string) float64 {
+	expectedCounts := map[string]int{}
+	expectedTokens := getKeywordTokens(expectedAnswer)
+	for _, token := range expectedTokens {
+		expectedCounts[token] += 1
+	}
+
+	answerTokens := getKeywordTokens(answer)
+	common := 0
+	for _, token := range answerTokens {
+		if expectedCounts[token] > 0 {
+			expectedCounts[token] -= 1
+			common += 1
+		}
+	}
+	if common == 0 {
+		return 0
+	}
+
+	precision := float64(common) / float64(len(answerTokens))
+	recall := float64(common) / float64(len(expectedTokens))
+	return 2 * precision * recall / (precision + recall)
+}
+
+// updateScores computes recall@k and MRR over the golden questions with an expected file or
+// chunk, and the answer match over the ones with an expected answer.
+func (evaluation *Evaluation) updateScores(goldenSet []*GoldenQuestion) {
+	retrievalCount := 0
+	hitCount := 0
+	reciprocalRankSum := 0.0
+	answerCount := 0
+	answerScoreSum := 0.0
+	for i, result := range evaluation.Results {
+		goldenQuestion := goldenSet[i]
+		if goldenQuestion.ExpectedFile != "" || goldenQuestion.ExpectedChunk != "" {
+			retrievalCount += 1
+			if result.Rank > 0 {
+				hitCount += 1
+				reciprocalRankSum += 1 / float64(result.Rank)
+			}
+		}
+		if goldenQuestion.ExpectedAnswer != "" {
+			answerCount += 1
+			answerScoreSum += result.AnswerScore
+		}
+	}
+
+	evaluation.RecallAtK = 0
+	evaluation.Mrr = 0
+	evaluation.AnswerMatch = 0
+	if retrievalCount != 0 {
+		evaluation.RecallAtK = float64(hitCount) / float64(retrievalCount)
+		evaluation.Mrr = reciprocalRankSum / float64(retrievalCount)
+	}
+	if answerCount != 0 {
+		evaluation.AnswerMatch = answerScoreSum / float64(answerCount)
+	}
+}
+
+func getEvaluationConfig(store *Store) *EvaluationConfig {
+	return &EvaluationConfig{
+		SplitProvider:     store.SplitProvider,
+		ChunkSize:         store.ChunkSize,
+		ChunkOverlap:      store.ChunkOverlap,
+		MinChunkSize:      store.MinChunkSize,
+		Tokenizer:         store.Tokenizer,
+		EmbeddingProvider: store.EmbeddingProvider,
+		SearchProvider:    store.SearchProvider,
+		RerankProvider:    store.RerankProvider,
+		ModelProvider:     store.ModelProvider,
+		RetrievalMode:     store.RetrievalMode,
+		TopK:              getStoreTopK(store),
+		MinSimilarity:     store.MinSimilarity,
+		UseMmr:            store.UseMmr,
+	}
+}
+
+// StartEvaluation runs the golden set of an evaluation task against the current configuration
+// of its store in the background, the returned evaluation is saved after each question.
+func StartEvaluation(task *Task) (*Evaluation, error) {
+	if task.Type != "Evaluation" {
+		return nil, fmt.Errorf("The task: %s should be of type \"Evaluation\", but got \"%s\"", task.GetId(), task.Type)
+	}
+	if len(task.GoldenSet) == 0 {
+		return nil, fmt.Errorf("The golden set of task: %s should not be empty", task.GetId())
+	}
+
+	store, err := GetStore(util.GetId("admin", task.Store))
+	if err != nil {
+		return nil, err
+	}
+	if store == nil {
+		return nil, fmt.Errorf("The store: %s is not found", task.Store)
+	}
+
+	evaluation := &Evaluation{
+		Owner:         task.Owner,
+		Name:          fmt.Sprintf("evaluation_%s", util.GetRandomName()),
+		CreatedTime:   util.GetCurrentTime(),
+		Task:          task.Name,
+		Store:         store.Name,
+		State:         "Running",
+		Config:        getEvaluationConfig(store),
+		K:             getStoreTopK(store),
+		QuestionCount: len(task.GoldenSet),
+		Results:       []*EvaluationResult{},
+	}
+
+	_, err = AddEvaluation(evaluation)
+	if err != nil {
+		return nil, err
+	}
+
+	util.SafeGoroutine(func() {
+		runEvaluation(evaluation, store, task.GoldenSet)
+	})
+	return evaluation, nil
+}
+
+// FailRunningEvaluations fails the evaluations interrupted by a restart, they can be started again from the task.
+func FailRunningEvaluations() error {
+	evaluations := []*Evaluation{}
+	err := adapter.engine.Find(&evaluations, &Evaluation{State: "Running"})
+	if err != nil {
+		return err
+	}
+
+	for _, evaluation := range evaluations {
+		evaluation.State = "Failed"
+		evaluation.Error = "The evaluation was interrupted by a restart"
+		evaluation.FinishedTime = util.GetCurrentTime()
+		err = evaluation.save()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func runEvaluation(evaluation *Evaluation, store *Store, goldenSet []*GoldenQuestion) {
+	err := runEvaluationQuestions(evaluation, store, goldenSet)
+	if err != nil {
+		evaluation.State = "Failed"
+		evaluation.Error = err.Error()
+	} else {
+		evaluation.State = "Finished"
+	}
+	evaluation.FinishedTime = util.GetCurrentTime()
+
+	err = evaluation.save()
+	if err != nil {
+		fmt.Printf("runEvaluation() error: %s\n", err.Error())
+	}
+}
+
+func hasExpectedAnswer(goldenSet []*GoldenQuestion) bool {
+	for _, goldenQuestion := range goldenSet {
+		if goldenQuestion.ExpectedAnswer != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func runEvaluationQuestions(evaluation *Evaluation, store *Store, goldenSet []*GoldenQuestion) error {
+	embeddingProvider, err := store.GetEmbeddingProvider()
+	if err != nil {
+		return err
+	}
+	if embeddingProvider == nil {
+		return fmt.Errorf("The embedding provider for store: %s is not found", store.GetId())
+	}
+
+	embeddingProviderObj, err := embeddingProvider.GetEmbeddingProvider()
+	if err != nil {
+		return err
+	}
+
+	var modelProviderObj model.ModelProvider
+	if hasExpectedAnswer(goldenSet) {
+		modelProvider, err := store.GetModelProvider()
+		if err != nil {
+			return err
+		}
+		if modelProvider == nil {
+			return fmt.Errorf("The model provider for store: %s is not found", store.GetId())
+		}
+
+		modelProviderObj, err = modelProvider.GetModelProvider()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, goldenQuestion := range goldenSet {
+		result, err := evaluateGoldenQuestion(evaluation, store, embeddingProvider, embeddingProviderObj, modelProviderObj, goldenQuestion)
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		evaluation.Results = append(evaluation.Results, result)
+		evaluation.updateScores(goldenSet)
+		err = evaluation.save()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func evaluateGoldenQuestion(evaluation *Evaluation, store *Store, embeddingProvider *Provider, embeddingProviderObj embedding.EmbeddingProvider, modelProviderObj model.ModelProvider, goldenQuestion *GoldenQuestion) (*EvaluationResult, error) {
+	result := &EvaluationResult{Question: goldenQuestion.Question, Files: []string{}}
+
+	queries, retrievalResult, err := GetRetrievalQueries(store, goldenQuestion.Question)
+	if err != nil {
+		return result, err
+	}
+	if retrievalResult != nil {
+		evaluation.TokenCount += retrievalResult.TotalTokenCount
+		evaluation.Price += retrievalResult.TotalPrice
+	}
+
+	knowledge, vectorScores, embeddingResult, rerankResult, err := GetNearestKnowledge(store, embeddingProvider, embeddingProviderObj, "admin", goldenQuestion.Question, queries, nil)
+	if err != nil && err.Error() != "no knowledge vectors found" {
+		return result, err
+	}
+	if embeddingResult != nil {
+		evaluation.TokenCount += embeddingResult.TokenCount
+		evaluation.Price += embeddingResult.Price
+		evaluation.Currency = embeddingResult.Currency
+	}
+	if rerankResult != nil {
+		evaluation.TokenCount += rerankResult.TokenCount
+		evaluation.Price += rerankResult.Price
+	}
+
+	vectors, err := getVectorCache(&Vector{Store: store.Name, Provider: embeddingProvider.Name})
+	if err != nil {
+		return result, err
+	}
+
+	vectorMap := map[string]*Vector{}
+	for _, vector := range vectors {
+		vectorMap[vector.Name] = vector
+	}
+
+	retrievedVectors := []*Vector{}
+	for _, vectorScore := range vectorScores {
+		if vector, ok := vectorMap[vectorScore.Vector]; ok {
+			retrievedVectors = append(retrievedVectors, vector)
+			result.Files = append(result.Files, vector.File)
+		}
+	}
+	result.Rank = getGoldenRank(goldenQuestion, retrievedVectors)
+
+	if goldenQuestion.ExpectedAnswer == "" {
+		return result, nil
+	}
+
+	answer, modelResult, err := getAnswerWithKnowledge(modelProviderObj, goldenQuestion.Question, store.Prompt, knowledge)
+	if err != nil {
+		return result, err
+	}
+
+	evaluation.TokenCount += modelResult.TotalTokenCount
+	evaluation.Price += modelResult.TotalPrice
+	result.Answer = answer
+	result.AnswerScore = getAnswerMatchScore(answer, goldenQuestion.ExpectedAnswer)
+	return result, nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseGoldenSet(t *testing.T) {
+	data := []byte("Question,ExpectedFile,ExpectedAnswer\nHow to reset my password?,docs/account.md,Click \"Forgot password\"\n,,\n")
+	goldenSet, err := ParseGoldenSet("golden.csv", data)
+	if err != nil {
+		panic(err)
+	}
+
+	if len(goldenSet) != 1 {
+		t.Fatalf("len(goldenSet) = %d, expected 1", len(goldenSet))
+	}
+	expected := GoldenQuestion{Question: "How to reset my password?", ExpectedFile: "docs/account.md", ExpectedAnswer: "Click \"Forgot password\""}
+	if *goldenSet[0] != expected {
+		t.Errorf("goldenSet[0] = %v, expected %v", *goldenSet[0], expected)
+	}
+
+	_, err = ParseGoldenSet("golden.csv", []byte("Text\nHow?\n"))
+	if err == nil {
+		t.Errorf("a golden set without a question column should be rejected")
+	}
+}
+
+func TestEvaluationScores(t *testing.T) {
+	vectors := []*Vector{
+		{Name: "vector_1", File: "docs/profile.md", Text: "Account settings and profile"},
+		{Name: "vector_2", File: "docs/account.md", Text: "Click  Forgot password on the login page"},
+	}
+
+	goldenSet := []*GoldenQuestion{
+		{Question: "How to reset my password?", ExpectedFile: "account.md", ExpectedChunk: "forgot password"},
+		{Question: "Where is my profile?", ExpectedFile: "docs/profile.md", ExpectedAnswer: "In the account settings"},
+		{Question: "What does ERR-1042 mean?", ExpectedFile: "docs/errors.md"},
+	}
+
+	evaluation := &Evaluation{}
+	for _, goldenQuestion := range goldenSet {
+		evaluation.Results = append(evaluation.Results, &EvaluationResult{Rank: getGoldenRank(goldenQuestion, vectors)})
+	}
+	if evaluation.Results[0].Rank != 2 || evaluation.Results[1].Rank != 1 || evaluation.Results[2].Rank != 0 {
+		t.Fatalf("ranks = [%d, %d, %d], expected [2, 1, 0]", evaluation.Results[0].Rank, evaluation.Results[1].Rank, evaluation.Results[2].Rank)
+	}
+
+	evaluation.Results[1].AnswerScore = getAnswerMatchScore("It is in the settings of your account", goldenSet[1].ExpectedAnswer)
+	if math.Abs(evaluation.Results[1].AnswerScore-2*(4.0/8)*(4.0/4)/(4.0/8+4.0/4)) > 1e-9 {
+		t.Errorf("answer score = %f, expected %f", evaluation.Results[1].AnswerScore, 2.0/3)
+	}
+
+	evaluation.updateScores(goldenSet)
+	if math.Abs(evaluation.RecallAtK-2.0/3) > 1e-9 || math.Abs(evaluation.Mrr-0.5) > 1e-9 {
+		t.Errorf("recall@k = %f, MRR = %f, expected %f and 0.5", evaluation.RecallAtK, evaluation.Mrr, 2.0/3)
+	}
+	if evaluation.AnswerMatch != evaluation.Results[1].AnswerScore {
+		t.Errorf("answer match = %f, expected %f", evaluation.AnswerMatch, evaluation.Results[1].AnswerScore)
+	}
+}
@@ -15,10 +15,10 @@
 package object
 
 import (
-	"encoding/json"
 	"fmt"
 	"sort"
 
+	"github.com/casibase/casibase/split"
 	"github.com/casibase/casibase/util"
 	"xorm.io/core"
 )
@@ -28,22 +28,57 @@ type Vector struct {
 	Name        string `xorm:"varchar(100) notnull pk" json:"name"`
 	CreatedTime string `xorm:"varchar(100)" json:"createdTime"`
 
-	DisplayName string  `xorm:"varchar(100)" json:"displayName"`
-	Store       string  `xorm:"varchar(100)" json:"store"`
-	Provider    string  `xorm:"varchar(100) index" json:"provider"`
-	File        string  `xorm:"varchar(100)" json:"file"`
-	Index       int     `json:"index"`
-	Text        string  `xorm:"mediumtext" json:"text"`
-	TokenCount  int     `json:"tokenCount"`
-	Price       float64 `json:"price"`
-	Currency    string  `xorm:"varchar(100)" json:"currency"`
-	Score       float32 `json:"score"`
-
-	Data      []float32 `xorm:"mediumtext" json:"data"`
-	Dimension int       `json:"dimension"`
+	DisplayName  string  `xorm:"varchar(100)" json:"displayName"`
+	Store        string  `xorm:"varchar(100)" json:"store"`
+	Provider     string  `xorm:"varchar(100) index" json:"provider"`
+	File         string  `xorm:"varchar(100)" json:"file"`
+	Index        int     `json:"index"`
+	Text         string  `xorm:"mediumtext" json:"text"`
+	Heading      string  `xorm:"varchar(1000)" json:"heading"`
+	Question     string  `xorm:"mediumtext" json:"question"`
+	Sheet        string  `xorm:"varchar(100)" json:"sheet"`
+	StartRow     int     `json:"startRow"`
+	EndRow       int     `json:"endRow"`
+	TokenCount   int     `json:"tokenCount"`
+	Price        float64 `json:"price"`
+	Currency     string  `xorm:"varchar(100)" json:"currency"`
+	Score        float32 `json:"score"`
+	Similarity   float32 `xorm:"-" json:"similarity"`
+	KeywordScore float32 `xorm:"-" json:"keywordScore"`
+	RerankScore  float32 `xorm:"-" json:"rerankScore"`
+	Freshness    float32 `xorm:"-" json:"freshness"`
+
+	// SplitTokenCount is the usage of the providers that split the chunk, like the sentence
+	// embeddings of the semantic splitter. It is included in Price, but not in TokenCount,
+	// which is the size of the embedded text.
+	SplitTokenCount int `json:"splitTokenCount"`
+	// KnowledgeTokenCount is the size of GetKnowledgeText() in the prompt. It differs from
+	// TokenCount when the question of a generated pair is embedded but its answer is given.
+	KnowledgeTokenCount int `json:"knowledgeTokenCount"`
+
+	FileModifiedTime string `xorm:"varchar(100)" json:"fileModifiedTime"`
+	FileSize         int64  `json:"fileSize"`
+	FileHash         string `xorm:"varchar(100)" json:"fileHash"`
+	Splitter         string `xorm:"varchar(100)" json:"splitter"`
+
+	Data      VectorData `xorm:"'data_binary' mediumblob" json:"data"`
+	Dimension int        `json:"dimension"`
 }
 
-var vectorCache map[string][]*Vector
+// GetKnowledgeText returns the text of the vector given to the model, with the path of the
+// headings it is under in its file.
+func (vector *Vector) GetKnowledgeText() string {
+	return split.GetSectionText(vector.Heading, vector.Text)
+}
+
+// getKnowledgeTokenCount returns the size of the vector in the prompt, the vectors indexed
+// before KnowledgeTokenCount existed only have the size of their embedded text.
+func (vector *Vector) getKnowledgeTokenCount() int {
+	if vector.KnowledgeTokenCount != 0 {
+		return vector.KnowledgeTokenCount
+	}
+	return vector.TokenCount
+}
 
 func GetGlobalVectors() ([]*Vector, error) {
 	vectors, err := getVectorCache(&Vector{})
@@ -82,21 +117,7 @@ func getVectorsByProvider(provider string) ([]*Vector, error) {
 }
 
 func getVector(owner string, name string) (*Vector, error) {
-	vector, existed := getFirstVectorCache(&Vector{Owner: owner, Name: name})
-	if existed {
-		return vector, nil
-	} else {
-		return nil, nil
-	}
-}
-
-func getVectorByIndex(owner string, store string, file string, index int) (*Vector, error) {
-	vector, existed := getFirstVectorCache(&Vector{Owner: owner, Store: store, File: file, Index: index})
-	if existed {
-		return vector, nil
-	} else {
-		return nil, nil
-	}
+	return getFirstVectorCache(&Vector{Owner: owner, Name: name})
 }
 
 func GetVector(id string) (*Vector, error) {
@@ -106,7 +127,7 @@ func GetVector(id string) (*Vector, error) {
 
 func UpdateVector(id string, vector *Vector) (bool, error) {
 	owner, name := util.GetOwnerAndNameFromId(id)
-	_, err := getVector(owner, name)
+	oldVector, err := getVector(owner, name)
 	if err != nil {
 		return false, err
 	}
@@ -118,24 +139,29 @@ func UpdateVector(id string, vector *Vector) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	updateVectorCache(owner, name, vector)
+	updateVectorCache(oldVector, vector)
+
+	err = updateSearchIndexVector(oldVector, vector)
+	if err != nil {
+		return false, err
+	}
 
 	// return affected != 0
 	return true, nil
 }
 
 func AddVector(vector *Vector) (bool, error) {
-	//err := Index.Add(util.GetId(vector.Owner, vector.Name), vector.Data)
-	//if err != nil {
-	//	return false, err
-	//}
-
 	affected, err := adapter.engine.Insert(vector)
 	if err != nil {
 		return false, err
 	}
 	if affected != 0 {
 		addVectorCache(vector)
+
+		err = addSearchIndexVector(vector)
+		if err != nil {
+			return false, err
+		}
 	}
 
 	return affected != 0, nil
@@ -148,6 +174,11 @@ func DeleteVector(vector *Vector) (bool, error) {
 	}
 	deleteVectorCache(vector)
 
+	err = deleteSearchIndexVector(vector)
+	if err != nil {
+		return false, err
+	}
+
 	return affected != 0, nil
 }
 
@@ -155,167 +186,64 @@ func (vector *Vector) GetId() string {
 	return fmt.Sprintf("%s/%s", vector.Owner, vector.Name)
 }
 
-func (vector *Vector) getVectorCacheKey() (string, error) {
-	marshal, err := json.Marshal(vector)
-	if err != nil {
-		return "", err
-	}
-	return string(marshal), nil
-}
-
-func getVectorCache(vector *Vector) ([]*Vector, error) {
-	if vectorCache == nil || vectorCache["all"] == nil || len(vectorCache) == 0 {
-		vectorCache = make(map[string][]*Vector)
-		err := syncVectorCache("")
-		if err != nil {
-			return nil, err
-		}
+// getVectorCache returns the vectors matching the non-empty fields of the filter. The
+// vector cache is partitioned by store, so the filter needs a store to be served from the
+// cache, the search paths always give one. A filter without a store, like the lookup of a
+// vector by its id or the lists of the admin pages, reads the database on every call.
+func getVectorCache(filter *Vector) ([]*Vector, error) {
+	var vectors []*Vector
+	var err error
+	if filter.Store != "" {
+		vectors, err = getStoreVectorCache(filter.Store)
+	} else {
+		vectors, err = getVectorsFromDb(filter)
 	}
-
-	key, err := vector.getVectorCacheKey()
 	if err != nil {
 		return nil, err
 	}
 
-	result := []*Vector{}
-	if vectorCache[key] == nil || len(vectorCache) == 0 {
-		err := syncSpecialVectorCache(vector)
-		if err != nil {
-			return nil, err
+	res := []*Vector{}
+	for _, vector := range vectors {
+		if isVectorMatched(filter, vector) {
+			res = append(res, vector)
 		}
 	}
-	result = vectorCache[key]
-
-	return result, nil
+	return res, nil
 }
 
-func getFirstVectorCache(vector *Vector) (*Vector, bool) {
-	allVectors := vectorCache["all"]
-	for _, v := range allVectors {
-		if vector.Owner != "" && vector.Owner != v.Owner {
-			continue
-		}
-		if vector.Name != "" && vector.Name != v.Name {
-			continue
-		}
-		if vector.Store != "" && vector.Store != v.Store {
-			continue
-		}
-		if vector.File != "" && vector.File != v.File {
-			continue
-		}
-		if vector.Index != 0 && vector.Index != v.Index {
-			continue
-		}
-		if vector.Provider != "" && vector.Provider != v.Provider {
-			continue
-		}
-		return v, true
-	}
-	return nil, false
-}
-
-func filterCacheMap() {
-	filteredMap := map[string][]*Vector{}
-	for key, value := range vectorCache {
-		if key == "all" {
-			filteredMap[key] = value
-		}
-	}
-	vectorCache = filteredMap
-}
-
-func addVectorCache(vector *Vector) {
-	filterCacheMap()
-	vectorCache["all"] = append(vectorCache["all"], vector)
-}
-
-func deleteVectorCache(vector *Vector) {
-	filterCacheMap()
-	for i, v := range vectorCache["all"] {
-		if v.Owner == vector.Owner && v.Name == vector.Name {
-			vectorCache["all"] = append(vectorCache["all"][:i], vectorCache["all"][i+1:]...)
-			break
-		}
+func getFirstVectorCache(filter *Vector) (*Vector, error) {
+	vectors, err := getVectorCache(filter)
+	if err != nil {
+		return nil, err
 	}
-}
 
-func updateVectorCache(owner string, name string, vector *Vector) {
-	filterCacheMap()
-	for i, v := range vectorCache["all"] {
-		if v.Owner == owner && v.Name == name {
-			vectorCache["all"][i] = vector
-			break
-		}
+	if len(vectors) == 0 {
+		return nil, nil
 	}
+	return vectors[0], nil
 }
 
-func syncVectorCache(storeName string) error {
-	if vectorCache == nil {
-		vectorCache = make(map[string][]*Vector)
-	}
-	var vectors []*Vector
-	var err error
-	if storeName == "" {
-		err = adapter.engine.Asc("owner").Desc("created_time").Find(&vectors)
-	} else {
-		err = adapter.engine.Asc("owner").Desc("created_time").Where("store = ?", storeName).Find(&vectors)
+func getVectorsFromDb(filter *Vector) ([]*Vector, error) {
+	vectors := []*Vector{}
+	session := adapter.engine.Asc("owner").Desc("created_time")
+	if filter.Owner != "" {
+		session = session.And("owner = ?", filter.Owner)
 	}
-	if err != nil {
-		return err
+	if filter.Name != "" {
+		session = session.And("name = ?", filter.Name)
 	}
-	if storeName == "" {
-		vectorCache["all"] = vectors
-	} else {
-		for i := range vectorCache["all"] {
-			if vectorCache["all"][i].Store != storeName {
-				vectors = append(vectors, vectorCache["all"][i])
-			}
-		}
-		vectorCache["all"] = vectors
+	if filter.File != "" {
+		session = session.And("file = ?", filter.File)
 	}
-	// clear other cache
-	filterCacheMap()
-	return nil
-}
-
-func syncSpecialVectorCache(vector *Vector) error {
-	allVectors := vectorCache["all"]
-	if allVectors == nil {
-		err := syncVectorCache("")
-		if err != nil {
-			return err
-		}
+	if filter.Provider != "" {
+		session = session.And("provider = ?", filter.Provider)
 	}
 
-	key, err := vector.getVectorCacheKey()
+	err := session.Find(&vectors)
 	if err != nil {
-		return err
-	}
-
-	for _, v := range allVectors {
-		if vector.Owner != "" && vector.Owner != v.Owner {
-			continue
-		}
-		if vector.Name != "" && vector.Name != v.Name {
-			continue
-		}
-		if vector.Store != "" && vector.Store != v.Store {
-			continue
-		}
-		if vector.File != "" && vector.File != v.File {
-			continue
-		}
-		if vector.Index != 0 && vector.Index != v.Index {
-			continue
-		}
-		if vector.Provider != "" && vector.Provider != v.Provider {
-			continue
-		}
-		vectorCache[key] = append(vectorCache[key], v)
+		return nil, err
 	}
-
-	return nil
+	return vectors, nil
 }
 
 func GetVectorCount(owner string, field string, value string) (int64, error) {
@@ -0,0 +1,299 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/casibase/casibase/conf"
+)
+
+// vectorCacheStore holds the vectors of one store. The stores are loaded from the
+// database at their first access, kept up to date by the single vector changes, and
+// the least recently used stores are evicted when the cache is over its memory limit.
+type vectorCacheStore struct {
+	vectors        []*Vector
+	size           int64
+	lastAccessTime int64
+}
+
+type VectorCacheStoreStats struct {
+	Store          string `json:"store"`
+	VectorCount    int    `json:"vectorCount"`
+	Size           int64  `json:"size"`
+	LastAccessTime string `json:"lastAccessTime"`
+}
+
+type VectorCacheStats struct {
+	HitCount      int64                    `json:"hitCount"`
+	MissCount     int64                    `json:"missCount"`
+	EvictionCount int64                    `json:"evictionCount"`
+	VectorCount   int                      `json:"vectorCount"`
+	Size          int64                    `json:"size"`
+	MemoryLimit   int64                    `json:"memoryLimit"`
+	Stores        []*VectorCacheStoreStats `json:"stores"`
+}
+
+var (
+	vectorCacheStores   = map[string]*vectorCacheStore{}
+	vectorCacheVersions = map[string]int64{}
+	vectorCacheSize     int64
+	vectorCacheMutex    sync.RWMutex
+
+	vectorCacheHitCount      int64
+	vectorCacheMissCount     int64
+	vectorCacheEvictionCount int64
+)
+
+// getVectorCacheMemoryLimit returns the "vectorCacheMemoryLimit" config in MB as bytes,
+// 0 means no limit.
+func getVectorCacheMemoryLimit() int64 {
+	limit, err := conf.GetConfigInt64("vectorCacheMemoryLimit")
+	if err != nil || limit <= 0 {
+		return 0
+	}
+	return limit * 1024 * 1024
+}
+
+// getVectorSize estimates the memory used by a cached vector.
+func getVectorSize(vector *Vector) int64 {
+	return int64(len(vector.Data)*4+len(vector.Text)+len(vector.Heading)+len(vector.Question)+len(vector.Sheet)+len(vector.Name)+len(vector.File)) + 256
+}
+
+func isVectorMatched(filter *Vector, v *Vector) bool {
+	if filter.Owner != "" && filter.Owner != v.Owner {
+		return false
+	}
+	if filter.Name != "" && filter.Name != v.Name {
+		return false
+	}
+	if filter.Store != "" && filter.Store != v.Store {
+		return false
+	}
+	if filter.File != "" && filter.File != v.File {
+		return false
+	}
+	if filter.Index != 0 && filter.Index != v.Index {
+		return false
+	}
+	if filter.Provider != "" && filter.Provider != v.Provider {
+		return false
+	}
+	return true
+}
+
+func getStoreVectorsFromDb(storeName string) ([]*Vector, error) {
+	vectors := []*Vector{}
+	err := adapter.engine.Asc("owner").Desc("created_time").Where("store = ?", storeName).Find(&vectors)
+	if err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+// getStoreVectorCache returns a copy of the cached vectors of a store, loading them
+// from the database on a miss.
+func getStoreVectorCache(storeName string) ([]*Vector, error) {
+	vectorCacheMutex.RLock()
+	cacheStore, ok := vectorCacheStores[storeName]
+	if ok {
+		atomic.StoreInt64(&cacheStore.lastAccessTime, time.Now().UnixNano())
+		res := make([]*Vector, len(cacheStore.vectors))
+		copy(res, cacheStore.vectors)
+		vectorCacheMutex.RUnlock()
+
+		atomic.AddInt64(&vectorCacheHitCount, 1)
+		return res, nil
+	}
+	version := vectorCacheVersions[storeName]
+	vectorCacheMutex.RUnlock()
+
+	atomic.AddInt64(&vectorCacheMissCount, 1)
+	vectors, err := getStoreVectorsFromDb(storeName)
+	if err != nil {
+		return nil, err
+	}
+
+	vectorCacheMutex.Lock()
+	defer vectorCacheMutex.Unlock()
+
+	// a vector of the store changed during the loading, so the result may be stale
+	// for the cache, it will be loaded again at the next access
+	if vectorCacheVersions[storeName] != version {
+		return vectors, nil
+	}
+	if _, ok = vectorCacheStores[storeName]; ok {
+		return vectors, nil
+	}
+
+	cacheStore = &vectorCacheStore{
+		vectors:        make([]*Vector, len(vectors)),
+		lastAccessTime: time.Now().UnixNano(),
+	}
+	copy(cacheStore.vectors, vectors)
+	for _, vector := range vectors {
+		cacheStore.size += getVectorSize(vector)
+	}
+
+	vectorCacheStores[storeName] = cacheStore
+	vectorCacheSize += cacheStore.size
+	evictVectorCache(storeName, getVectorCacheMemoryLimit())
+	return vectors, nil
+}
+
+// evictVectorCache drops the least recently used stores until the cache fits in the
+// memory limit. The store just accessed is kept even if it alone exceeds the limit.
+func evictVectorCache(keptStoreName string, limit int64) {
+	if limit == 0 {
+		return
+	}
+
+	for vectorCacheSize > limit {
+		oldestStoreName := ""
+		oldestTime := int64(0)
+		for storeName, cacheStore := range vectorCacheStores {
+			if storeName == keptStoreName {
+				continue
+			}
+
+			lastAccessTime := atomic.LoadInt64(&cacheStore.lastAccessTime)
+			if oldestStoreName == "" || lastAccessTime < oldestTime {
+				oldestStoreName = storeName
+				oldestTime = lastAccessTime
+			}
+		}
+		if oldestStoreName == "" {
+			return
+		}
+
+		vectorCacheSize -= vectorCacheStores[oldestStoreName].size
+		delete(vectorCacheStores, oldestStoreName)
+		vectorCacheVersions[oldestStoreName] += 1
+		vectorCacheEvictionCount += 1
+	}
+}
+
+func addVectorCache(vector *Vector) {
+	vectorCacheMutex.Lock()
+	defer vectorCacheMutex.Unlock()
+
+	addVectorCacheInternal(vector)
+	evictVectorCache(vector.Store, getVectorCacheMemoryLimit())
+}
+
+func addVectorCacheInternal(vector *Vector) {
+	vectorCacheVersions[vector.Store] += 1
+	cacheStore, ok := vectorCacheStores[vector.Store]
+	if !ok {
+		return
+	}
+
+	size := getVectorSize(vector)
+	cacheStore.vectors = append(cacheStore.vectors, vector)
+	cacheStore.size += size
+	vectorCacheSize += size
+}
+
+func deleteVectorCache(vector *Vector) {
+	vectorCacheMutex.Lock()
+	defer vectorCacheMutex.Unlock()
+
+	deleteVectorCacheInternal(vector.Owner, vector.Name, vector.Store)
+}
+
+func deleteVectorCacheInternal(owner string, name string, storeName string) {
+	vectorCacheVersions[storeName] += 1
+	cacheStore, ok := vectorCacheStores[storeName]
+	if !ok {
+		return
+	}
+
+	for i, v := range cacheStore.vectors {
+		if v.Owner == owner && v.Name == name {
+			size := getVectorSize(v)
+			cacheStore.vectors = append(cacheStore.vectors[:i], cacheStore.vectors[i+1:]...)
+			cacheStore.size -= size
+			vectorCacheSize -= size
+			break
+		}
+	}
+}
+
+// updateVectorCache replaces the old vector in place, or moves it when its store changed.
+func updateVectorCache(oldVector *Vector, vector *Vector) {
+	if oldVector == nil {
+		addVectorCache(vector)
+		return
+	}
+
+	vectorCacheMutex.Lock()
+	defer vectorCacheMutex.Unlock()
+
+	if oldVector.Store != vector.Store {
+		deleteVectorCacheInternal(oldVector.Owner, oldVector.Name, oldVector.Store)
+		addVectorCacheInternal(vector)
+		evictVectorCache(vector.Store, getVectorCacheMemoryLimit())
+		return
+	}
+
+	vectorCacheVersions[vector.Store] += 1
+	cacheStore, ok := vectorCacheStores[vector.Store]
+	if !ok {
+		return
+	}
+
+	for i, v := range cacheStore.vectors {
+		if v.Owner == oldVector.Owner && v.Name == oldVector.Name {
+			size := getVectorSize(vector) - getVectorSize(v)
+			cacheStore.vectors[i] = vector
+			cacheStore.size += size
+			vectorCacheSize += size
+			break
+		}
+	}
+	evictVectorCache(vector.Store, getVectorCacheMemoryLimit())
+}
+
+func GetVectorCacheStats() *VectorCacheStats {
+	vectorCacheMutex.RLock()
+	defer vectorCacheMutex.RUnlock()
+
+	res := &VectorCacheStats{
+		HitCount:      atomic.LoadInt64(&vectorCacheHitCount),
+		MissCount:     atomic.LoadInt64(&vectorCacheMissCount),
+		EvictionCount: vectorCacheEvictionCount,
+		Size:          vectorCacheSize,
+		MemoryLimit:   getVectorCacheMemoryLimit(),
+		Stores:        []*VectorCacheStoreStats{},
+	}
+
+	for storeName, cacheStore := range vectorCacheStores {
+		res.VectorCount += len(cacheStore.vectors)
+		res.Stores = append(res.Stores, &VectorCacheStoreStats{
+			Store:          storeName,
+			VectorCount:    len(cacheStore.vectors),
+			Size:           cacheStore.size,
+			LastAccessTime: time.Unix(0, atomic.LoadInt64(&cacheStore.lastAccessTime)).Format(time.RFC3339),
+		})
+	}
+	sort.Slice(res.Stores, func(i, j int) bool {
+		return res.Stores[i].Store < res.Stores[j].Store
+	})
+
+	return res
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import "testing"
+
+func setVectorCacheStore(storeName string, vectors []*Vector, lastAccessTime int64) {
+	cacheStore := &vectorCacheStore{vectors: vectors, lastAccessTime: lastAccessTime}
+	for _, vector := range vectors {
+		cacheStore.size += getVectorSize(vector)
+	}
+	vectorCacheStores[storeName] = cacheStore
+	vectorCacheSize += cacheStore.size
+}
+
+func TestVectorCache(t *testing.T) {
+	vectorCacheStores = map[string]*vectorCacheStore{}
+	vectorCacheVersions = map[string]int64{}
+	vectorCacheSize = 0
+
+	vector1 := &Vector{Owner: "admin", Name: "vector_1", Store: "store_1", Data: []float32{1, 0}}
+	vector2 := &Vector{Owner: "admin", Name: "vector_2", Store: "store_2", Data: []float32{0, 1}}
+	setVectorCacheStore("store_1", []*Vector{vector1}, 1)
+	setVectorCacheStore("store_2", []*Vector{vector2}, 2)
+
+	vector3 := &Vector{Owner: "admin", Name: "vector_3", Store: "store_1", Text: "text"}
+	addVectorCacheInternal(vector3)
+	if len(vectorCacheStores["store_1"].vectors) != 2 {
+		t.Fatalf("added vector should be cached in store_1")
+	}
+
+	// a vector of a store that is not loaded is left to the next load
+	addVectorCacheInternal(&Vector{Owner: "admin", Name: "vector_4", Store: "store_3"})
+	if _, ok := vectorCacheStores["store_3"]; ok {
+		t.Fatalf("store_3 should not be loaded by an added vector")
+	}
+
+	movedVector := *vector3
+	movedVector.Store = "store_2"
+	updateVectorCache(vector3, &movedVector)
+	if len(vectorCacheStores["store_1"].vectors) != 1 || len(vectorCacheStores["store_2"].vectors) != 2 {
+		t.Fatalf("updated vector should be moved from store_1 to store_2")
+	}
+
+	deleteVectorCache(vector2)
+	if len(vectorCacheStores["store_2"].vectors) != 1 || vectorCacheStores["store_2"].vectors[0].Name != "vector_3" {
+		t.Fatalf("deleted vector should be removed from store_2")
+	}
+
+	expectedSize := getVectorSize(vector1) + getVectorSize(&movedVector)
+	if vectorCacheSize != expectedSize {
+		t.Fatalf("vectorCacheSize = %d, expected %d", vectorCacheSize, expectedSize)
+	}
+
+	// store_1 is the least recently used one, store_2 is kept as the accessed store
+	evictVectorCache("store_2", getVectorSize(&movedVector))
+	if _, ok := vectorCacheStores["store_1"]; ok {
+		t.Fatalf("store_1 should be evicted")
+	}
+	if _, ok := vectorCacheStores["store_2"]; !ok {
+		t.Fatalf("store_2 should be kept")
+	}
+
+	stats := GetVectorCacheStats()
+	if stats.VectorCount != 1 || stats.EvictionCount != 1 || len(stats.Stores) != 1 {
+		t.Errorf("GetVectorCacheStats() = %+v, expected 1 vector, 1 eviction and 1 store", stats)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// VectorData is the embedding of a vector. It is stored in the database as
+// little-endian float32 values instead of a JSON array, which is about a third
+// of the size and needs no parsing when the vector cache is loaded.
+type VectorData []float32
+
+func encodeVectorData(data []float32) []byte {
+	res := make([]byte, len(data)*4)
+	for i, val := range data {
+		binary.LittleEndian.PutUint32(res[i*4:], math.Float32bits(val))
+	}
+	return res
+}
+
+func decodeVectorData(b []byte) ([]float32, error) {
+	if len(b)%4 != 0 {
+		return nil, fmt.Errorf("The vector data length: [%d] should be a multiple of 4", len(b))
+	}
+
+	res := make([]float32, len(b)/4)
+	for i := range res {
+		res[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[i*4:]))
+	}
+	return res, nil
+}
+
+func (d *VectorData) FromDB(b []byte) error {
+	if len(b) == 0 {
+		*d = nil
+		return nil
+	}
+
+	// the legacy JSON array format, kept readable for the rows not migrated yet
+	if b[0] == '[' {
+		var data []float32
+		err := json.Unmarshal(b, &data)
+		if err != nil {
+			return err
+		}
+
+		*d = data
+		return nil
+	}
+
+	data, err := decodeVectorData(b)
+	if err != nil {
+		return err
+	}
+
+	*d = data
+	return nil
+}
+
+func (d *VectorData) ToDB() ([]byte, error) {
+	if *d == nil {
+		return nil, nil
+	}
+	return encodeVectorData(*d), nil
+}
+
+// migrateVectorData moves the embeddings saved as JSON arrays in the legacy "data"
+// column to the binary "data_binary" column, and clears the legacy column.
+func migrateVectorData() error {
+	tableName := adapter.engine.TableName(new(Vector))
+	isExisted, err := adapter.engine.Dialect().IsColumnExist(adapter.engine.DB(), context.Background(), tableName, "data")
+	if err != nil {
+		return err
+	}
+	if !isExisted {
+		return nil
+	}
+
+	count := 0
+	for {
+		rows, err := adapter.engine.Table(tableName).Cols("owner", "name", "data").Where("data IS NOT NULL AND data_binary IS NULL").Limit(1000).QueryString()
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		for _, row := range rows {
+			var data VectorData
+			err = data.FromDB([]byte(row["data"]))
+			if err != nil {
+				fmt.Printf("Failed to parse the data of vector: [%s/%s], dropping it: %s\n", row["owner"], row["name"], err.Error())
+				data = nil
+			}
+
+			var b []byte
+			if len(data) != 0 {
+				b = encodeVectorData(data)
+			}
+
+			_, err = adapter.engine.Exec(fmt.Sprintf("UPDATE %s SET data_binary = ?, data = NULL WHERE owner = ? AND name = ?", tableName), b, row["owner"], row["name"])
+			if err != nil {
+				return err
+			}
+		}
+
+		count += len(rows)
+		fmt.Printf("Migrated the data of %d vectors to the binary format\n", count)
+	}
+
+	return nil
+}
@@ -17,17 +17,26 @@ package object
 import (
 	"context"
 	"fmt"
+	"math"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/casibase/casibase/embedding"
 	"github.com/casibase/casibase/model"
+	"github.com/casibase/casibase/rerank"
 	"github.com/casibase/casibase/split"
 	"github.com/casibase/casibase/storage"
 	"github.com/casibase/casibase/txt"
 	"github.com/casibase/casibase/util"
-	"golang.org/x/time/rate"
+	"xorm.io/core"
+)
+
+const (
+	// defaultEmbeddingBatchSize fits the smallest batch limit of the providers with a batch API.
+	defaultEmbeddingBatchSize   = 10
+	defaultEmbeddingConcurrency = 4
 )
 
 func filterTextFiles(files []*storage.Object) []*storage.Object {
@@ -47,140 +56,597 @@ func filterTextFiles(files []*storage.Object) []*storage.Object {
 	return res
 }
 
-func addEmbeddedVector(embeddingProviderObj embedding.EmbeddingProvider, text string, storeName string, fileName string, index int, embeddingProviderName string, modelSubType string) (bool, error) {
-	data, embeddingResult, err := queryVectorSafe(embeddingProviderObj, text)
+// VectorRefreshSummary counts the vectors changed by a store refresh.
+type VectorRefreshSummary struct {
+	Added   int `json:"added"`
+	Updated int `json:"updated"`
+	Removed int `json:"removed"`
+	Skipped int `json:"skipped"`
+
+	mutex sync.Mutex
+}
+
+func (summary *VectorRefreshSummary) add(added int, updated int, removed int, skipped int) {
+	summary.mutex.Lock()
+	defer summary.mutex.Unlock()
+
+	summary.Added += added
+	summary.Updated += updated
+	summary.Removed += removed
+	summary.Skipped += skipped
+}
+
+// embeddingSection is a text section of a file waiting to be embedded, oldVector is its
+// vector indexed from a previous version of the file.
+type embeddingSection struct {
+	index     int
+	section   *split.TextSection
+	oldVector *Vector
+}
+
+// getEmbeddingText returns the text of a section to embed: the question of a generated pair,
+// or the text with its heading path, so that a section is found by the topic of its headings.
+func (section *embeddingSection) getEmbeddingText() string {
+	if section.section.Question != "" {
+		return section.section.Question
+	}
+	return split.GetSectionText(section.section.Heading, section.section.Text)
+}
+
+// getKnowledgeTokenCounts returns the sizes of the sections in the prompt, only the sections
+// whose knowledge text is not the embedded text, like the generated pairs, are counted again.
+func getKnowledgeTokenCounts(sections []*embeddingSection, texts []string, defaultEmbeddingResults []*embedding.EmbeddingResult, modelSubType string) ([]int, error) {
+	res := []int{}
+	for i, section := range sections {
+		knowledgeText := split.GetSectionText(section.section.Heading, section.section.Text)
+		if knowledgeText == texts[i] {
+			res = append(res, defaultEmbeddingResults[i].TokenCount)
+			continue
+		}
+
+		defaultEmbeddingResult, err := embedding.GetDefaultEmbeddingResult(modelSubType, knowledgeText)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, defaultEmbeddingResult.TokenCount)
+	}
+	return res, nil
+}
+
+// isVectorOfSection returns whether a vector was indexed from the same text section.
+func isVectorOfSection(vector *Vector, textSection *split.TextSection) bool {
+	return vector.Text == textSection.Text && vector.Heading == textSection.Heading && vector.Question == textSection.Question &&
+		vector.Sheet == textSection.Sheet && vector.StartRow == textSection.StartRow && vector.EndRow == textSection.EndRow
+}
+
+func getDefaultEmbeddingResults(texts []string, modelSubType string) ([]*embedding.EmbeddingResult, error) {
+	res := []*embedding.EmbeddingResult{}
+	for _, text := range texts {
+		defaultEmbeddingResult, err := embedding.GetDefaultEmbeddingResult(modelSubType, text)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, defaultEmbeddingResult)
+	}
+	return res, nil
+}
+
+// splitEmbeddingResult shares the usage of a batch request between its texts in
+// proportion to their estimated token counts.
+func splitEmbeddingResult(embeddingResult *embedding.EmbeddingResult, defaultEmbeddingResults []*embedding.EmbeddingResult) []*embedding.EmbeddingResult {
+	totalTokenCount := 0
+	for _, defaultEmbeddingResult := range defaultEmbeddingResults {
+		totalTokenCount += defaultEmbeddingResult.TokenCount
+	}
+
+	res := []*embedding.EmbeddingResult{}
+	for _, defaultEmbeddingResult := range defaultEmbeddingResults {
+		share := 1.0 / float64(len(defaultEmbeddingResults))
+		if totalTokenCount != 0 {
+			share = float64(defaultEmbeddingResult.TokenCount) / float64(totalTokenCount)
+		}
+
+		result := &embedding.EmbeddingResult{}
+		if embeddingResult != nil {
+			result.TokenCount = int(math.Round(float64(embeddingResult.TokenCount) * share))
+			result.Price = embeddingResult.Price * share
+			result.Currency = embeddingResult.Currency
+		}
+
+		if result.TokenCount == 0 {
+			result.TokenCount = defaultEmbeddingResult.TokenCount
+		}
+		if result.Price == 0 {
+			result.Price = defaultEmbeddingResult.Price
+		}
+		if result.Currency == "" {
+			result.Currency = defaultEmbeddingResult.Currency
+		}
+		res = append(res, result)
+	}
+	return res
+}
+
+func addEmbeddedVectors(sections []*embeddingSection, data [][]float32, embeddingResults []*embedding.EmbeddingResult, knowledgeTokenCounts []int, storeName string, file *storage.Object, fileHash string, splitter string, embeddingProviderName string) ([]*Vector, error) {
+	res := []*Vector{}
+	for i, section := range sections {
+		text := section.section.Text
+		displayName := text
+		if len(text) > 25 {
+			displayName = string([]rune(text)[:25])
+		}
+
+		vector := &Vector{
+			Owner:               "admin",
+			Name:                fmt.Sprintf("vector_%s", util.GetRandomName()),
+			CreatedTime:         util.GetCurrentTime(),
+			DisplayName:         displayName,
+			Store:               storeName,
+			Provider:            embeddingProviderName,
+			File:                file.Key,
+			Index:               section.index,
+			Text:                text,
+			Heading:             section.section.Heading,
+			Question:            section.section.Question,
+			Sheet:               section.section.Sheet,
+			StartRow:            section.section.StartRow,
+			EndRow:              section.section.EndRow,
+			TokenCount:          embeddingResults[i].TokenCount,
+			SplitTokenCount:     section.section.TokenCount,
+			KnowledgeTokenCount: knowledgeTokenCounts[i],
+			Price:               embeddingResults[i].Price + section.section.Price,
+			Currency:            embeddingResults[i].Currency,
+			FileModifiedTime:    file.LastModified,
+			FileSize:            file.Size,
+			FileHash:            fileHash,
+			Splitter:            splitter,
+			Data:                data[i],
+			Dimension:           len(data[i]),
+		}
+
+		_, err := AddVector(vector)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, vector)
+	}
+	return res, nil
+}
+
+// getSplitterKey returns the split settings recorded on the vectors of a file. The default
+// settings give an empty key, so the files indexed before the settings existed are kept.
+func getSplitterKey(splitConfig *split.SplitConfig) string {
+	if (splitConfig.Type == "" || splitConfig.Type == "Default") && splitConfig.ChunkSize == 0 && splitConfig.ChunkOverlap == 0 && splitConfig.MinChunkSize == 0 && splitConfig.Tokenizer == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s/%d/%d/%d/%s", splitConfig.Type, splitConfig.ChunkSize, splitConfig.ChunkOverlap, splitConfig.MinChunkSize, splitConfig.Tokenizer)
+}
+
+// getSplitTextGenerator asks the model provider of the store to generate the question/answer
+// pairs of the "Generated QA" splitter.
+func getSplitTextGenerator(modelProviderObj model.ModelProvider) split.TextGenerator {
+	return func(prompt string) (string, *model.ModelResult, error) {
+		return getAnswerWithKnowledge(modelProviderObj, prompt, "", []*model.RawMessage{})
+	}
+}
+
+// getSplitTextEmbedder embeds the sentences of the semantic splitter in batches through the
+// embedding cache, like the sections, so that splitting a file again costs nothing.
+func getSplitTextEmbedder(ctx context.Context, embeddingProviderObj embedding.EmbeddingProvider, embeddingProvider *Provider, modelSubType string, batchSize int, limiter *ProviderRateLimiter) split.TextEmbedder {
+	return func(texts []string) ([][]float32, []*embedding.EmbeddingResult, error) {
+		data := [][]float32{}
+		embeddingResults := []*embedding.EmbeddingResult{}
+		for i := 0; i < len(texts); i += batchSize {
+			end := i + batchSize
+			if end > len(texts) {
+				end = len(texts)
+			}
+
+			defaultEmbeddingResults, err := getDefaultEmbeddingResults(texts[i:end], modelSubType)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			batchData, batchEmbeddingResults, err := queryVectorsWithCache(ctx, embeddingProvider, embeddingProviderObj, texts[i:end], defaultEmbeddingResults, limiter)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			data = append(data, batchData...)
+			embeddingResults = append(embeddingResults, batchEmbeddingResults...)
+		}
+		return data, embeddingResults, nil
+	}
+}
+
+// isFileIndexed returns whether all the vectors of a file were indexed from the same
+// version of it in the storage and split with the same settings.
+func isFileIndexed(file *storage.Object, vectors []*Vector, splitter string) bool {
+	if len(vectors) == 0 || file.LastModified == "" {
+		return false
+	}
+
+	for _, vector := range vectors {
+		if vector.FileModifiedTime != file.LastModified || vector.FileSize != file.Size || vector.Splitter != splitter {
+			return false
+		}
+	}
+	return true
+}
+
+// updateVectorFileInfo records the file version of a vector whose text is unchanged,
+// without embedding it again.
+func updateVectorFileInfo(vector *Vector, file *storage.Object, fileHash string, splitter string) error {
+	if vector.FileModifiedTime == file.LastModified && vector.FileSize == file.Size && vector.FileHash == fileHash && vector.Splitter == splitter {
+		return nil
+	}
+
+	newVector := *vector
+	newVector.FileModifiedTime = file.LastModified
+	newVector.FileSize = file.Size
+	newVector.FileHash = fileHash
+	newVector.Splitter = splitter
+
+	_, err := adapter.engine.ID(core.PK{vector.Owner, vector.Name}).Cols("file_modified_time", "file_size", "file_hash", "splitter").Update(&newVector)
+	if err != nil {
+		return err
+	}
+
+	updateVectorCache(vector, &newVector)
+	return nil
+}
+
+// matchFileVectors maps the section indexes of a file to its indexed vectors, the vectors
+// of trailing indexes that no longer exist and the duplicated ones are returned as stale.
+func matchFileVectors(sectionCount int, vectors []*Vector) (map[int]*Vector, []*Vector) {
+	indexVectorMap := map[int]*Vector{}
+	staleVectors := []*Vector{}
+	for _, vector := range vectors {
+		if _, ok := indexVectorMap[vector.Index]; ok || vector.Index >= sectionCount {
+			staleVectors = append(staleVectors, vector)
+		} else {
+			indexVectorMap[vector.Index] = vector
+		}
+	}
+	return indexVectorMap, staleVectors
+}
+
+// isTableSplit returns whether a file is a spreadsheet split by its rows, the QA split types
+// read it as a text instead.
+func isTableSplit(file *storage.Object, splitConfig *split.SplitConfig) bool {
+	return txt.IsTableFileType(filepath.Ext(file.Key)) && splitConfig.Type != "QA" && splitConfig.Type != "Generated QA"
+}
+
+func getFileSplitterKey(file *storage.Object, splitConfig *split.SplitConfig) string {
+	if isTableSplit(file, splitConfig) {
+		return fmt.Sprintf("Table/%d/%s", splitConfig.ChunkSize, splitConfig.Tokenizer)
+	}
+	return getSplitterKey(splitConfig)
+}
+
+func getFileTableSections(file *storage.Object, splitConfig *split.SplitConfig) ([]*split.TextSection, string, error) {
+	tables, err := txt.GetParsedTablesFromUrl(file.Url, filepath.Ext(file.Key))
+	if err != nil {
+		return nil, "", err
+	}
+
+	tableSplitProvider, err := split.GetTableSplitProvider(splitConfig)
 	if err != nil {
-		return false, err
+		return nil, "", err
 	}
 
-	displayName := text
-	if len(text) > 25 {
-		displayName = string([]rune(text)[:25])
+	textSections, err := tableSplitProvider.SplitTables(tables)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// the rendered rows stand for the text of the file
+	texts := []string{}
+	for _, textSection := range textSections {
+		texts = append(texts, split.GetSectionText(textSection.Heading, textSection.Text))
 	}
+	return textSections, util.GetSha256Hash(strings.Join(texts, "\n\n")), nil
+}
 
-	tokenCount := 0
-	price := 0.0
-	currency := ""
-	if embeddingResult != nil {
-		tokenCount = embeddingResult.TokenCount
-		price = embeddingResult.Price
-		currency = embeddingResult.Currency
+func getFileTextSections(file *storage.Object, splitConfig *split.SplitConfig) ([]*split.TextSection, string, error) {
+	if isTableSplit(file, splitConfig) {
+		return getFileTableSections(file, splitConfig)
 	}
 
-	defaultEmbeddingResult, err := embedding.GetDefaultEmbeddingResult(modelSubType, text)
+	fileExt := filepath.Ext(file.Key)
+	text, err := txt.GetParsedTextFromUrl(file.Url, fileExt)
 	if err != nil {
-		return false, err
+		return nil, "", err
 	}
 
-	if tokenCount == 0 {
-		tokenCount = defaultEmbeddingResult.TokenCount
+	fileSplitConfig := *splitConfig
+	if fileSplitConfig.Type == "" {
+		fileSplitConfig.Type = "Default"
 	}
-	if price == 0 {
-		price = defaultEmbeddingResult.Price
+
+	if strings.HasPrefix(file.Key, "QA") && fileExt == ".docx" {
+		fileSplitConfig.Type = "QA"
 	}
-	if currency == "" {
-		currency = defaultEmbeddingResult.Currency
+
+	splitProvider, err := split.GetSplitProvider(&fileSplitConfig)
+	if err != nil {
+		return nil, "", err
 	}
 
-	vector := &Vector{
-		Owner:       "admin",
-		Name:        fmt.Sprintf("vector_%s", util.GetRandomName()),
-		CreatedTime: util.GetCurrentTime(),
-		DisplayName: displayName,
-		Store:       storeName,
-		Provider:    embeddingProviderName,
-		File:        fileName,
-		Index:       index,
-		Text:        text,
-		TokenCount:  tokenCount,
-		Price:       price,
-		Currency:    currency,
-		Data:        data,
-		Dimension:   len(data),
+	textSections, err := split.GetTextSections(splitProvider, text)
+	if err != nil {
+		return nil, "", err
 	}
-	return AddVector(vector)
+
+	return textSections, util.GetSha256Hash(text), nil
 }
 
-func addVectorsForStore(storageProviderObj storage.StorageProvider, embeddingProviderObj embedding.EmbeddingProvider, prefix string, storeName string, splitProviderName string, embeddingProviderName string, modelSubType string, limit int) (bool, error) {
-	var affected bool
+// addVectorsForStore re-indexes the files of a store incrementally: unchanged files and
+// sections are skipped, changed sections are embedded again, and the vectors of deleted
+// files and of the sections that no longer exist are removed. When it runs in a job, the
+// progress is saved to the job, the files finished by a previous run are skipped and the
+// failed files don't stop the other ones.
+func addVectorsForStore(ctx context.Context, storageProviderObj storage.StorageProvider, embeddingProviderObj embedding.EmbeddingProvider, prefix string, storeName string, splitConfig *split.SplitConfig, embeddingProvider *Provider, modelSubType string, limiter *ProviderRateLimiter, batchSize int, concurrency int, job *Job) (*VectorRefreshSummary, error) {
+	summary := &VectorRefreshSummary{}
+	if job != nil && job.Summary != nil {
+		summary = job.Summary
+	}
 
 	files, err := storageProviderObj.ListObjects(prefix)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	files = filterTextFiles(files)
 
-	timeLimiter := rate.NewLimiter(rate.Every(time.Minute), limit)
+	vectors, err := getVectorCache(&Vector{Store: storeName, Provider: embeddingProvider.Name})
+	if err != nil {
+		return nil, err
+	}
+
+	fileVectorsMap := map[string][]*Vector{}
+	for _, vector := range vectors {
+		if strings.HasPrefix(vector.File, prefix) {
+			fileVectorsMap[vector.File] = append(fileVectorsMap[vector.File], vector)
+		}
+	}
+
+	err = job.setFiles(files)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	fileSplitConfig := *splitConfig
+	fileSplitConfig.EmbedTexts = getSplitTextEmbedder(ctx, embeddingProviderObj, embeddingProvider, modelSubType, batchSize, limiter)
+
+	type fileTask struct {
+		file        *storage.Object
+		fileVectors []*Vector
+	}
+
+	var firstErr error
+	var errMutex sync.Mutex
+	var wg sync.WaitGroup
+	fileTaskChan := make(chan *fileTask)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range fileTaskChan {
+				err := addVectorsForFile(ctx, embeddingProviderObj, task.file, task.fileVectors, storeName, &fileSplitConfig, embeddingProvider, modelSubType, batchSize, limiter, summary, job)
+				if err != nil && job != nil && ctx.Err() == nil {
+					fmt.Printf("Generating embedding for store: [%s], file: [%s]: %s\n", storeName, task.file.Key, err.Error())
+					err = job.finishFile(task.file.Key, err)
+				} else if err == nil {
+					err = job.finishFile(task.file.Key, nil)
+				}
+
+				if err != nil {
+					errMutex.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					errMutex.Unlock()
+					cancel()
+				}
+			}
+		}()
+	}
+
 	for _, file := range files {
-		var text string
-		fileExt := filepath.Ext(file.Key)
-		text, err = txt.GetParsedTextFromUrl(file.Url, fileExt)
-		if err != nil {
-			return false, err
+		fileVectors := fileVectorsMap[file.Key]
+		delete(fileVectorsMap, file.Key)
+
+		if job.isFileFinished(file.Key) {
+			continue
 		}
 
-		splitProviderType := splitProviderName
-		if splitProviderType == "" {
-			splitProviderType = "Default"
+		select {
+		case fileTaskChan <- &fileTask{file: file, fileVectors: fileVectors}:
+		case <-ctx.Done():
 		}
+	}
+	close(fileTaskChan)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 
-		if strings.HasPrefix(file.Key, "QA") && fileExt == ".docx" {
-			splitProviderType = "QA"
+	// the files left are deleted from the storage
+	for file, fileVectors := range fileVectorsMap {
+		fmt.Printf("Removing vectors for store: [%s], file: [%s]: %s\n", storeName, file, "Deleted from the storage")
+		for _, vector := range fileVectors {
+			_, err = DeleteVector(vector)
+			if err != nil {
+				return nil, err
+			}
+			summary.add(0, 0, 1, 0)
 		}
+	}
+
+	err = refreshSearchIndexes(storeName)
+	if err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
 
-		var splitProvider split.SplitProvider
-		splitProvider, err = split.GetSplitProvider(splitProviderType)
+func addVectorsForFile(ctx context.Context, embeddingProviderObj embedding.EmbeddingProvider, file *storage.Object, fileVectors []*Vector, storeName string, splitConfig *split.SplitConfig, embeddingProvider *Provider, modelSubType string, batchSize int, limiter *ProviderRateLimiter, summary *VectorRefreshSummary, job *Job) error {
+	splitter := getFileSplitterKey(file, splitConfig)
+	var textSections []*split.TextSection
+	var fileHash string
+	var err error
+	if splitConfig.Type == "Generated QA" {
+		// the approved pairs may change without the file, so the file is never skipped
+		textSections, fileHash, err = getFileQaSections(storeName, file, splitConfig, splitter)
 		if err != nil {
-			return false, err
+			return err
+		}
+	} else {
+		if isFileIndexed(file, fileVectors, splitter) {
+			fmt.Printf("Generating embedding for store: [%s], file: [%s]: %s\n", storeName, file.Key, "Skipped due to not modified")
+			summary.add(0, 0, 0, len(fileVectors))
+			return nil
 		}
 
-		var textSections []string
-		textSections, err = splitProvider.SplitText(text)
+		textSections, fileHash, err = getFileTextSections(file, splitConfig)
 		if err != nil {
-			return false, err
+			return err
+		}
+	}
+
+	err = job.startFile(file.Key, len(textSections))
+	if err != nil {
+		return err
+	}
+
+	indexVectorMap, staleVectors := matchFileVectors(len(textSections), fileVectors)
+	pendingSections := []*embeddingSection{}
+	for i, textSection := range textSections {
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
 
-		for i, textSection := range textSections {
-			var vector *Vector
-			vector, err = getVectorByIndex("admin", storeName, file.Key, i)
+		vector := indexVectorMap[i]
+		if vector != nil && isVectorOfSection(vector, textSection) {
+			fmt.Printf("[%d/%d] Generating embedding for store: [%s], file: [%s], index: [%d]: %s\n", i+1, len(textSections), storeName, file.Key, i, "Skipped due to already exists")
+			err = updateVectorFileInfo(vector, file, fileHash, splitter)
 			if err != nil {
-				return false, err
+				return err
 			}
 
-			if vector != nil {
-				fmt.Printf("[%d/%d] Generating embedding for store: [%s], file: [%s], index: [%d]: %s\n", i+1, len(textSections), storeName, file.Key, i, "Skipped due to already exists")
-				continue
+			summary.add(0, 0, 0, 1)
+			err = job.finishSection(file.Key, nil)
+			if err != nil {
+				return err
 			}
+			continue
+		}
 
-			if timeLimiter.Allow() {
-				fmt.Printf("[%d/%d] Generating embedding for store: [%s], file: [%s], index: [%d]: %s\n", i+1, len(textSections), storeName, file.Key, i, textSection)
-				affected, err = addEmbeddedVector(embeddingProviderObj, textSection, storeName, file.Key, i, embeddingProviderName, modelSubType)
-			} else {
-				err = timeLimiter.Wait(context.Background())
-				if err != nil {
-					return false, err
-				}
-
-				fmt.Printf("[%d/%d] Generating embedding for store: [%s], file: [%s], index: [%d]: %s\n", i+1, len(textSections), storeName, file.Key, i, textSection)
-				affected, err = addEmbeddedVector(embeddingProviderObj, textSection, storeName, file.Key, i, embeddingProviderName, modelSubType)
+		pendingSections = append(pendingSections, &embeddingSection{index: i, section: textSection, oldVector: vector})
+		if len(pendingSections) >= batchSize {
+			err = addEmbeddedSections(ctx, embeddingProviderObj, pendingSections, len(textSections), file, fileHash, splitter, storeName, embeddingProvider, modelSubType, limiter, summary, job)
+			if err != nil {
+				return err
 			}
+			pendingSections = []*embeddingSection{}
+		}
+	}
+
+	if len(pendingSections) != 0 {
+		err = addEmbeddedSections(ctx, embeddingProviderObj, pendingSections, len(textSections), file, fileHash, splitter, storeName, embeddingProvider, modelSubType, limiter, summary, job)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, vector := range staleVectors {
+		_, err = DeleteVector(vector)
+		if err != nil {
+			return err
 		}
+		summary.add(0, 0, 1, 0)
 	}
-	// after add vector, sync
-	err = syncVectorCache(storeName)
+
+	return nil
+}
+
+// addEmbeddedSections embeds the sections of a file in one batch request, the sections found
+// in the embedding cache are not sent to the provider.
+func addEmbeddedSections(ctx context.Context, embeddingProviderObj embedding.EmbeddingProvider, sections []*embeddingSection, sectionCount int, file *storage.Object, fileHash string, splitter string, storeName string, embeddingProvider *Provider, modelSubType string, limiter *ProviderRateLimiter, summary *VectorRefreshSummary, job *Job) error {
+	texts := []string{}
+	for _, section := range sections {
+		texts = append(texts, section.getEmbeddingText())
+	}
+
+	defaultEmbeddingResults, err := getDefaultEmbeddingResults(texts, modelSubType)
 	if err != nil {
-		return false, err
+		return err
+	}
+
+	for _, section := range sections {
+		fmt.Printf("[%d/%d] Generating embedding for store: [%s], file: [%s], index: [%d]: %s\n", section.index+1, sectionCount, storeName, file.Key, section.index, section.section.Text)
 	}
 
-	return affected, err
+	data, embeddingResults, err := queryVectorsWithCache(ctx, embeddingProvider, embeddingProviderObj, texts, defaultEmbeddingResults, limiter)
+	if err != nil {
+		return err
+	}
+
+	knowledgeTokenCounts, err := getKnowledgeTokenCounts(sections, texts, defaultEmbeddingResults, modelSubType)
+	if err != nil {
+		return err
+	}
+
+	vectors, err := addEmbeddedVectors(sections, data, embeddingResults, knowledgeTokenCounts, storeName, file, fileHash, splitter, embeddingProvider.Name)
+	if err != nil {
+		return err
+	}
+
+	for i, section := range sections {
+		if section.oldVector == nil {
+			summary.add(1, 0, 0, 0)
+		} else {
+			// the old vector is only removed after the new one is added, so the section keeps answering meanwhile
+			_, err = DeleteVector(section.oldVector)
+			if err != nil {
+				return err
+			}
+			summary.add(0, 1, 0, 0)
+		}
+
+		err = job.finishSection(file.Key, vectors[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-func getRelatedVectors(provider string) ([]*Vector, error) {
-	vectors, err := getVectorsByProvider(provider)
+func getRelatedVectors(provider string, filter *SearchFilter) ([]*Vector, error) {
+	var vectors []*Vector
+	var err error
+	if filter != nil && filter.Store != "" {
+		vectors, err = getVectorCache(&Vector{Store: filter.Store, Provider: provider})
+	} else {
+		vectors, err = getVectorsByProvider(provider)
+	}
 	if err != nil {
 		return nil, err
 	}
+
+	vectors = filter.filterVectors(vectors)
 	if len(vectors) == 0 {
 		return nil, fmt.Errorf("no knowledge vectors found")
 	}
@@ -217,27 +683,110 @@ func queryVectorSafe(embeddingProvider embedding.EmbeddingProvider, text string)
 	}
 }
 
-func GetNearestKnowledge(embeddingProvider *Provider, embeddingProviderObj embedding.EmbeddingProvider, owner string, text string) ([]*model.RawMessage, []VectorScore, *embedding.EmbeddingResult, error) {
-	qVector, embeddingResult, err := queryVectorSafe(embeddingProviderObj, text)
+func queryVectorsWithContext(embeddingProvider embedding.EmbeddingProvider, texts []string, timeout int) ([][]float32, *embedding.EmbeddingResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(30+timeout*2+len(texts))*time.Second)
+	defer cancel()
+	vectors, embeddingResult, err := embeddingProvider.QueryVectors(texts, ctx)
+	return vectors, embeddingResult, err
+}
+
+func queryVectorsSafe(embeddingProvider embedding.EmbeddingProvider, texts []string) ([][]float32, *embedding.EmbeddingResult, error) {
+	var res [][]float32
+	var embeddingResult *embedding.EmbeddingResult
+	var err error
+	for i := 0; i < 10; i++ {
+		res, embeddingResult, err = queryVectorsWithContext(embeddingProvider, texts, i)
+		if err != nil {
+			if i > 0 {
+				fmt.Printf("\tFailed (%d): %s\n", i+1, err.Error())
+			}
+		} else {
+			break
+		}
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(res) != len(texts) {
+		return nil, nil, fmt.Errorf("The embedding count: %d doesn't match the text count: %d", len(res), len(texts))
+	}
+	return res, embeddingResult, nil
+}
+
+// GetNearestKnowledge searches the knowledge of a question with each of the queries from
+// GetRetrievalQueries and merges their results, the question itself is used for the
+// keyword search of a hypothetical answer and for the rerank.
+func GetNearestKnowledge(store *Store, embeddingProvider *Provider, embeddingProviderObj embedding.EmbeddingProvider, owner string, text string, queries []string, filter *SearchFilter) ([]*model.RawMessage, []VectorScore, *embedding.EmbeddingResult, *rerank.RerankResult, error) {
+	if len(queries) == 0 {
+		queries = []string{text}
+	}
+
+	searchProvider, err := GetSearchProvider(store.SearchProvider, owner, store)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, nil, nil, err
 	}
-	if qVector == nil || len(qVector) == 0 {
-		return nil, nil, nil, fmt.Errorf("no qVector found")
+
+	if filter == nil {
+		filter = &SearchFilter{}
 	}
+	filter.Store = store.Name
+	filter.PropertiesMap = store.PropertiesMap
+
+	embeddingResult := &embedding.EmbeddingResult{}
+	results := [][]Vector{}
+	for _, query := range queries {
+		qVector, queryEmbeddingResult, err := queryVectorWithCache(embeddingProvider, embeddingProviderObj, query)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		if qVector == nil || len(qVector) == 0 {
+			return nil, nil, nil, nil, fmt.Errorf("no qVector found")
+		}
+
+		if queryEmbeddingResult != nil {
+			embeddingResult.TokenCount += queryEmbeddingResult.TokenCount
+			embeddingResult.Price += queryEmbeddingResult.Price
+			embeddingResult.Currency = queryEmbeddingResult.Currency
+		}
+
+		searchText := query
+		if store.RetrievalMode == "HyDE" {
+			searchText = text
+		}
 
-	searchProvider, err := GetSearchProvider("Default", owner)
+		vectors, err := searchProvider.Search(embeddingProvider.Name, qVector, searchText, filter, getStoreCandidateCount(store))
+		if err != nil {
+			if err.Error() == "no knowledge vectors found" {
+				// another query may still find the knowledge
+				continue
+			} else {
+				return nil, nil, nil, nil, err
+			}
+		}
+		results = append(results, vectors)
+	}
+
+	vectors := mergeSearchResults(results, getStoreCandidateCount(store))
+	if len(vectors) == 0 {
+		return nil, nil, embeddingResult, nil, fmt.Errorf("no knowledge vectors found")
+	}
+
+	vectors = filterVectorsBySimilarity(vectors, store.MinSimilarity)
+
+	vectors, rerankResult, err := rerankVectors(store, text, vectors)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, nil, nil, err
 	}
 
-	vectors, err := searchProvider.Search(embeddingProvider.Name, qVector)
+	vectors, err = applyFreshness(store, embeddingProvider.Name, vectors, rerankResult != nil)
 	if err != nil {
-		if err.Error() == "no knowledge vectors found" {
-			return nil, nil, embeddingResult, err
-		} else {
-			return nil, nil, nil, err
-		}
+		return nil, nil, nil, nil, err
+	}
+
+	vectors = selectTopVectors(store, vectors, rerankResult != nil)
+	if len(vectors) == 0 {
+		return nil, nil, embeddingResult, rerankResult, fmt.Errorf("no knowledge vectors found")
 	}
 
 	vectorScores := []VectorScore{}
@@ -248,15 +797,19 @@ func GetNearestKnowledge(embeddingProvider *Provider, embeddingProviderObj embed
 		// }
 
 		vectorScores = append(vectorScores, VectorScore{
-			Vector: vector.Name,
-			Score:  vector.Score,
+			Vector:       vector.Name,
+			Score:        vector.Score,
+			Similarity:   vector.Similarity,
+			KeywordScore: vector.KeywordScore,
+			RerankScore:  vector.RerankScore,
+			Freshness:    vector.Freshness,
 		})
 		knowledge = append(knowledge, &model.RawMessage{
-			Text:           vector.Text,
+			Text:           vector.GetKnowledgeText(),
 			Author:         "System",
-			TextTokenCount: vector.TokenCount,
+			TextTokenCount: vector.getKnowledgeTokenCount(),
 		})
 	}
 
-	return knowledge, vectorScores, embeddingResult, nil
+	return knowledge, vectorScores, embeddingResult, rerankResult, nil
 }
@@ -0,0 +1,157 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"math"
+)
+
+// QuantizedVector is a lossy compact copy of a vector for in-memory search. "Int8" keeps
+// one byte per dimension with a shared scale, "Float16" keeps two bytes per dimension.
+type QuantizedVector struct {
+	Int8    []int8
+	Float16 []uint16
+	Scale   float32
+}
+
+func checkQuantization(quantization string) error {
+	if quantization != "" && quantization != "Int8" && quantization != "Float16" {
+		return fmt.Errorf("The quantization: [%s] is not supported, it should be empty, \"Int8\" or \"Float16\"", quantization)
+	}
+	return nil
+}
+
+func quantizeVector(data []float32, quantization string) *QuantizedVector {
+	if quantization == "Int8" {
+		return quantizeVectorToInt8(data)
+	} else if quantization == "Float16" {
+		res := &QuantizedVector{Float16: make([]uint16, len(data))}
+		for i, val := range data {
+			res.Float16[i] = float32ToFloat16(val)
+		}
+		return res
+	}
+	return nil
+}
+
+// quantizeVectorToInt8 maps [-maxAbs, maxAbs] linearly to [-127, 127].
+func quantizeVectorToInt8(data []float32) *QuantizedVector {
+	maxAbs := float32(0)
+	for _, val := range data {
+		if abs := float32(math.Abs(float64(val))); abs > maxAbs {
+			maxAbs = abs
+		}
+	}
+
+	res := &QuantizedVector{Int8: make([]int8, len(data))}
+	if maxAbs == 0 {
+		return res
+	}
+
+	res.Scale = maxAbs / 127
+	for i, val := range data {
+		res.Int8[i] = int8(math.Round(float64(val / res.Scale)))
+	}
+	return res
+}
+
+func (q *QuantizedVector) dot(target []float32) float32 {
+	res := float32(0)
+	if q.Float16 != nil {
+		for i, val := range q.Float16 {
+			res += float16ToFloat32(val) * target[i]
+		}
+		return res
+	}
+
+	for i, val := range q.Int8 {
+		res += float32(val) * target[i]
+	}
+	return res * q.Scale
+}
+
+func (q *QuantizedVector) dequantize() []float32 {
+	if q.Float16 != nil {
+		res := make([]float32, len(q.Float16))
+		for i, val := range q.Float16 {
+			res[i] = float16ToFloat32(val)
+		}
+		return res
+	}
+
+	res := make([]float32, len(q.Int8))
+	for i, val := range q.Int8 {
+		res[i] = float32(val) * q.Scale
+	}
+	return res
+}
+
+// float32ToFloat16 converts to IEEE 754 half precision, rounding to the nearest value.
+func float32ToFloat16(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int32((bits>>23)&0xff) - 127 + 15
+	mant := bits & 0x7fffff
+
+	if (bits>>23)&0xff == 0xff {
+		if mant != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+	}
+	if exp >= 0x1f {
+		return sign | 0x7c00
+	}
+	if exp <= 0 {
+		if exp < -10 {
+			return sign
+		}
+
+		mant |= 0x800000
+		shift := uint32(14 - exp)
+		half := uint16(mant >> shift)
+		if (mant>>(shift-1))&1 != 0 {
+			half += 1
+		}
+		return sign | half
+	}
+
+	// a carry out of the mantissa correctly increments the exponent
+	half := sign | uint16(exp)<<10 | uint16(mant>>13)
+	if mant&0x1000 != 0 {
+		half += 1
+	}
+	return half
+}
+
+func float16ToFloat32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+
+	if exp == 0 {
+		// zero or subnormal: mant * 2^-24
+		res := float32(mant) / (1 << 24)
+		if sign != 0 {
+			return -res
+		}
+		return res
+	}
+	if exp == 0x1f {
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	}
+	return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+}
@@ -0,0 +1,133 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestVectorData(t *testing.T) {
+	data := VectorData{0.25, -1.5, 3.1415927, 0}
+
+	b, err := data.ToDB()
+	if err != nil {
+		panic(err)
+	}
+	if len(b) != len(data)*4 {
+		t.Errorf("encoded length = %d, expected %d", len(b), len(data)*4)
+	}
+
+	var decoded VectorData
+	err = decoded.FromDB(b)
+	if err != nil {
+		panic(err)
+	}
+	if !reflect.DeepEqual(decoded, data) {
+		t.Errorf("decoded data = %v, expected %v", decoded, data)
+	}
+
+	var legacy VectorData
+	err = legacy.FromDB([]byte("[0.25,-1.5,3.1415927,0]"))
+	if err != nil {
+		panic(err)
+	}
+	if !reflect.DeepEqual(legacy, data) {
+		t.Errorf("data decoded from JSON = %v, expected %v", legacy, data)
+	}
+}
+
+func TestFloat16(t *testing.T) {
+	for _, f := range []float32{0, 1, -2, 0.5, 65504, 0.000061035156, -0.33325195} {
+		if res := float16ToFloat32(float32ToFloat16(f)); res != f {
+			t.Errorf("float16 round trip of %v = %v", f, res)
+		}
+	}
+
+	for _, f := range []float32{0.1, -0.7071, 0.0003, 12.345} {
+		res := float16ToFloat32(float32ToFloat16(f))
+		if math.Abs(float64(res-f)) > math.Abs(float64(f))/1024 {
+			t.Errorf("float16 round trip of %v = %v, the error is too large", f, res)
+		}
+	}
+}
+
+func getTopNames(similarities []float32, n int) map[int]bool {
+	indexes := make([]int, len(similarities))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		return similarities[indexes[i]] > similarities[indexes[j]]
+	})
+
+	res := map[int]bool{}
+	for _, i := range indexes[:n] {
+		res[i] = true
+	}
+	return res
+}
+
+func TestQuantizedSearchAccuracy(t *testing.T) {
+	data := getRandomVectors(2000, 64, 1)
+	queries := getRandomVectors(50, 64, 2)
+	n := 10
+
+	for _, quantization := range []string{"Int8", "Float16"} {
+		quantizedVectors := []*QuantizedVector{}
+		for _, vec := range data {
+			quantizedVectors = append(quantizedVectors, quantizeVector(normalizeVector(vec), quantization))
+		}
+
+		hitCount := 0
+		for _, q := range queries {
+			q = normalizeVector(q)
+			similarities := []float32{}
+			quantizedSimilarities := []float32{}
+			for i, vec := range data {
+				similarities = append(similarities, dot(q, normalizeVector(vec)))
+				quantizedSimilarities = append(quantizedSimilarities, quantizedVectors[i].dot(q))
+			}
+
+			expected := getTopNames(similarities, n)
+			for i := range getTopNames(quantizedSimilarities, n) {
+				if expected[i] {
+					hitCount += 1
+				}
+			}
+		}
+
+		recall := float64(hitCount) / float64(len(queries)*n)
+		if recall < 0.95 {
+			t.Errorf("%s quantized recall@%d = %f, expected >= 0.95", quantization, n, recall)
+		}
+
+		index := newHnswIndex(16, 200, quantization)
+		for i, vec := range data {
+			err := index.add(fmt.Sprintf("vector_%d", i), vec)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		hnswRecall := getHnswRecall(index, data, queries, 5, 64)
+		if hnswRecall < 0.85 {
+			t.Errorf("%s quantized HNSW recall@5 = %f, expected >= 0.85", quantization, hnswRecall)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"math"
+	"testing"
+
+	"github.com/casibase/casibase/embedding"
+	"github.com/casibase/casibase/storage"
+)
+
+func TestIsFileIndexed(t *testing.T) {
+	file := &storage.Object{Key: "a.md", LastModified: "2024-01-02T00:00:00Z", Size: 100}
+	vectors := []*Vector{
+		{Index: 0, FileModifiedTime: "2024-01-02T00:00:00Z", FileSize: 100},
+		{Index: 1, FileModifiedTime: "2024-01-02T00:00:00Z", FileSize: 100},
+	}
+	if !isFileIndexed(file, vectors, "") {
+		t.Errorf("isFileIndexed() = false, expected true for the same version")
+	}
+
+	vectors[1].FileModifiedTime = "2024-01-01T00:00:00Z"
+	if isFileIndexed(file, vectors, "") {
+		t.Errorf("isFileIndexed() = true, expected false when a vector was indexed from an older version")
+	}
+
+	// vectors indexed before the file versions were recorded
+	if isFileIndexed(file, []*Vector{{Index: 0}}, "") {
+		t.Errorf("isFileIndexed() = true, expected false for a vector without file version")
+	}
+}
+
+func TestMatchFileVectors(t *testing.T) {
+	vectors := []*Vector{
+		{Name: "vector_0", Index: 0},
+		{Name: "vector_1", Index: 1},
+		{Name: "vector_1_duplicated", Index: 1},
+		{Name: "vector_2", Index: 2},
+	}
+
+	indexVectorMap, staleVectors := matchFileVectors(2, vectors)
+	if len(indexVectorMap) != 2 || indexVectorMap[0].Name != "vector_0" || indexVectorMap[1].Name != "vector_1" {
+		t.Errorf("matchFileVectors() returned %d indexed vectors, expected vector_0 and vector_1", len(indexVectorMap))
+	}
+	if len(staleVectors) != 2 || staleVectors[0].Name != "vector_1_duplicated" || staleVectors[1].Name != "vector_2" {
+		t.Errorf("matchFileVectors() returned %d stale vectors, expected vector_1_duplicated and vector_2", len(staleVectors))
+	}
+}
+
+func TestSplitEmbeddingResult(t *testing.T) {
+	defaultEmbeddingResults := []*embedding.EmbeddingResult{
+		{TokenCount: 2, Price: 0.0002, Currency: "USD"},
+		{TokenCount: 8, Price: 0.0008, Currency: "USD"},
+	}
+	embeddingResult := &embedding.EmbeddingResult{TokenCount: 100, Price: 0.01, Currency: "USD"}
+
+	res := splitEmbeddingResult(embeddingResult, defaultEmbeddingResults)
+	if res[0].TokenCount != 20 || res[1].TokenCount != 80 {
+		t.Errorf("splitEmbeddingResult() token counts = [%d, %d], expected [20, 80]", res[0].TokenCount, res[1].TokenCount)
+	}
+
+	if math.Abs(res[0].Price-0.002) > 1e-9 || math.Abs(res[1].Price-0.008) > 1e-9 || res[1].Currency != "USD" {
+		t.Errorf("splitEmbeddingResult() prices = [%f, %f] %s, expected [0.002, 0.008] USD", res[0].Price, res[1].Price, res[1].Currency)
+	}
+
+	// the estimates are used when the provider doesn't report its usage
+	res = splitEmbeddingResult(&embedding.EmbeddingResult{}, defaultEmbeddingResults)
+	if res[0].TokenCount != 2 || res[1].Price != 0.0008 {
+		t.Errorf("splitEmbeddingResult() = [%d, %f], expected the estimated [2, 0.0008]", res[0].TokenCount, res[1].Price)
+	}
+}
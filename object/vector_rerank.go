@@ -0,0 +1,81 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package object
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/casibase/casibase/rerank"
+	"github.com/casibase/casibase/util"
+)
+
+func getRerankProviderObj(store *Store) (rerank.RerankProvider, error) {
+	provider, err := GetProvider(util.GetId("admin", store.RerankProvider))
+	if err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("The rerank provider: %s is not found", store.RerankProvider)
+	}
+	if provider.Category != "Rerank" {
+		return nil, fmt.Errorf("The provider: %s should be a rerank provider, but got category: %s", store.RerankProvider, provider.Category)
+	}
+
+	return provider.GetRerankProvider()
+}
+
+// rerankVectors reorders the candidates by the relevance given by the store's rerank
+// provider, the candidates left out by the provider are dropped.
+func rerankVectors(store *Store, text string, vectors []Vector) ([]Vector, *rerank.RerankResult, error) {
+	if store.RerankProvider == "" || len(vectors) == 0 {
+		return vectors, nil, nil
+	}
+
+	rerankProviderObj, err := getRerankProviderObj(store)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	documents := []string{}
+	for _, vector := range vectors {
+		documents = append(documents, vector.GetKnowledgeText())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	scores, rerankResult, err := rerankProviderObj.Rerank(text, documents, len(documents), ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return applyRerankScores(vectors, scores), rerankResult, nil
+}
+
+func applyRerankScores(vectors []Vector, scores []rerank.RerankScore) []Vector {
+	res := []Vector{}
+	for _, score := range scores {
+		if score.Index < 0 || score.Index >= len(vectors) {
+			continue
+		}
+
+		vector := vectors[score.Index]
+		vector.RerankScore = score.Score
+		res = append(res, vector)
+	}
+	return res
+}
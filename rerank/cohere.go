@@ -0,0 +1,98 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rerank
+
+import (
+	"context"
+
+	cohere "github.com/cohere-ai/cohere-go/v2"
+	cohereclient "github.com/cohere-ai/cohere-go/v2/client"
+)
+
+type CohereRerankProvider struct {
+	subType   string
+	secretKey string
+}
+
+func NewCohereRerankProvider(subType string, secretKey string) (*CohereRerankProvider, error) {
+	return &CohereRerankProvider{
+		subType:   subType,
+		secretKey: secretKey,
+	}, nil
+}
+
+func (p *CohereRerankProvider) GetPricing() string {
+	return `URL:
+https://cohere.com/pricing
+
+Rerank models:
+
+| Models  | Per 1,000 searches |
+|---------|--------------------|
+| default | $2                 |
+`
+}
+
+func (p *CohereRerankProvider) calculatePrice(res *RerankResult, searchUnits int) error {
+	pricePerSearch := 0.002
+	res.Price = float64(searchUnits) * pricePerSearch
+	res.Currency = "USD"
+	return nil
+}
+
+func (p *CohereRerankProvider) Rerank(query string, documents []string, topN int, ctx context.Context) ([]RerankScore, *RerankResult, error) {
+	client := cohereclient.NewClient(
+		cohereclient.WithToken(p.secretKey),
+	)
+
+	items := []*cohere.RerankRequestDocumentsItem{}
+	for _, document := range documents {
+		items = append(items, cohere.NewRerankRequestDocumentsItemFromString(document))
+	}
+
+	resp, err := client.Rerank(ctx, &cohere.RerankRequest{
+		Model:     &p.subType,
+		Query:     query,
+		Documents: items,
+		TopN:      &topN,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scores := []RerankScore{}
+	for _, result := range resp.Results {
+		scores = append(scores, RerankScore{Index: result.Index, Score: float32(result.RelevanceScore)})
+	}
+	sortRerankScores(scores)
+
+	searchUnits := 0
+	rerankResult := &RerankResult{}
+	if resp.Meta != nil && resp.Meta.BilledUnits != nil {
+		if resp.Meta.BilledUnits.SearchUnits != nil {
+			searchUnits = int(*resp.Meta.BilledUnits.SearchUnits)
+		}
+		if resp.Meta.BilledUnits.InputTokens != nil {
+			rerankResult.TokenCount = int(*resp.Meta.BilledUnits.InputTokens)
+		}
+	}
+
+	err = p.calculatePrice(rerankResult, searchUnits)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return scores, rerankResult, nil
+}
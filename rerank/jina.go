@@ -0,0 +1,64 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rerank
+
+import "context"
+
+type JinaRerankProvider struct {
+	subType string
+	apiKey  string
+}
+
+func NewJinaRerankProvider(subType string, apiKey string) (*JinaRerankProvider, error) {
+	p := &JinaRerankProvider{
+		subType: subType,
+		apiKey:  apiKey,
+	}
+	return p, nil
+}
+
+func (p *JinaRerankProvider) GetPricing() string {
+	return `URL:
+https://jina.ai/reranker/
+
+Rerank models:
+
+| Models        | Per 1,000,000 tokens |
+|---------------|----------------------|
+| jina-reranker | $0.02                |
+`
+}
+
+func (p *JinaRerankProvider) calculatePrice(res *RerankResult) error {
+	pricePerThousandTokens := 0.00002
+	res.Price = getPrice(res.TokenCount, pricePerThousandTokens)
+	res.Currency = "USD"
+	return nil
+}
+
+func (p *JinaRerankProvider) Rerank(query string, documents []string, topN int, ctx context.Context) ([]RerankScore, *RerankResult, error) {
+	scores, tokenCount, err := postRerankRequest(ctx, "https://api.jina.ai/v1/rerank", p.apiKey, p.subType, query, documents, topN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rerankResult := &RerankResult{TokenCount: tokenCount}
+	err = p.calculatePrice(rerankResult)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return scores, rerankResult, nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rerank
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// LocalRerankProvider calls a self-hosted rerank server exposing an OpenAI-style
+// "/v1/rerank" endpoint, like vLLM, Xinference or Text Embeddings Inference.
+type LocalRerankProvider struct {
+	subType     string
+	secretKey   string
+	providerUrl string
+}
+
+func NewLocalRerankProvider(subType string, secretKey string, providerUrl string) (*LocalRerankProvider, error) {
+	p := &LocalRerankProvider{
+		subType:     subType,
+		secretKey:   secretKey,
+		providerUrl: providerUrl,
+	}
+	return p, nil
+}
+
+func (p *LocalRerankProvider) GetPricing() string {
+	return "Free"
+}
+
+func (p *LocalRerankProvider) getUrl() string {
+	url := strings.TrimSuffix(p.providerUrl, "/")
+	if strings.HasSuffix(url, "/rerank") {
+		return url
+	}
+	return url + "/rerank"
+}
+
+func (p *LocalRerankProvider) Rerank(query string, documents []string, topN int, ctx context.Context) ([]RerankScore, *RerankResult, error) {
+	if p.providerUrl == "" {
+		return nil, nil, fmt.Errorf("the provider URL of the local rerank provider should not be empty")
+	}
+
+	scores, tokenCount, err := postRerankRequest(ctx, p.getUrl(), p.secretKey, p.subType, query, documents, topN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rerankResult := &RerankResult{TokenCount: tokenCount, Currency: "USD"}
+	return scores, rerankResult, nil
+}
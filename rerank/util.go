@@ -0,0 +1,108 @@
+// Copyright 2023 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rerank
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+)
+
+func getPrice(tokenCount int, pricePerThousandTokens float64) float64 {
+	res := (float64(tokenCount) / 1000.0) * pricePerThousandTokens
+	res = math.Round(res*1e8) / 1e8
+	return res
+}
+
+func sortRerankScores(scores []RerankScore) {
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scores[i].Score > scores[j].Score
+	})
+}
+
+// postRerankRequest calls a rerank endpoint in the format shared by Jina and most
+// self-hosted rerank servers, returning the scores and the total token count.
+func postRerankRequest(ctx context.Context, url string, apiKey string, model string, query string, documents []string, topN int) ([]RerankScore, int, error) {
+	if query == "" {
+		return nil, 0, fmt.Errorf("query cannot be empty")
+	}
+
+	payload := map[string]interface{}{
+		"model":     model,
+		"query":     query,
+		"documents": documents,
+		"top_n":     topN,
+	}
+
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to marshal payload: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("failed to get valid response, status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var apiResponse struct {
+		Usage struct {
+			TotalTokens int `json:"total_tokens"`
+		} `json:"usage"`
+		Results []struct {
+			Index          int     `json:"index"`
+			RelevanceScore float64 `json:"relevance_score"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(body, &apiResponse); err != nil {
+		return nil, 0, fmt.Errorf("failed to unmarshal response: %v", err)
+	}
+
+	res := []RerankScore{}
+	for _, result := range apiResponse.Results {
+		if result.Index < 0 || result.Index >= len(documents) {
+			return nil, 0, fmt.Errorf("the rerank result index: %d is out of range", result.Index)
+		}
+		res = append(res, RerankScore{Index: result.Index, Score: float32(result.RelevanceScore)})
+	}
+	sortRerankScores(res)
+
+	return res, apiResponse.Usage.TotalTokens, nil
+}
@@ -75,6 +75,7 @@ func initAPI() {
 	beego.Router("/api/add-store", &controllers.ApiController{}, "POST:AddStore")
 	beego.Router("/api/delete-store", &controllers.ApiController{}, "POST:DeleteStore")
 	beego.Router("/api/refresh-store-vectors", &controllers.ApiController{}, "POST:RefreshStoreVectors")
+	beego.Router("/api/migrate-store-embedding-provider", &controllers.ApiController{}, "POST:MigrateStoreEmbeddingProvider")
 
 	beego.Router("/api/get-storage-providers", &controllers.ApiController{}, "GET:GetStorageProviders")
 
@@ -84,6 +85,8 @@ func initAPI() {
 	beego.Router("/api/update-provider", &controllers.ApiController{}, "POST:UpdateProvider")
 	beego.Router("/api/add-provider", &controllers.ApiController{}, "POST:AddProvider")
 	beego.Router("/api/delete-provider", &controllers.ApiController{}, "POST:DeleteProvider")
+	beego.Router("/api/get-embedding-cache-stats", &controllers.ApiController{}, "GET:GetEmbeddingCacheStats")
+	beego.Router("/api/purge-embedding-cache", &controllers.ApiController{}, "POST:PurgeEmbeddingCache")
 
 	beego.Router("/api/get-global-vectors", &controllers.ApiController{}, "GET:GetGlobalVectors")
 	beego.Router("/api/get-vectors", &controllers.ApiController{}, "GET:GetVectors")
@@ -91,6 +94,7 @@ func initAPI() {
 	beego.Router("/api/update-vector", &controllers.ApiController{}, "POST:UpdateVector")
 	beego.Router("/api/add-vector", &controllers.ApiController{}, "POST:AddVector")
 	beego.Router("/api/delete-vector", &controllers.ApiController{}, "POST:DeleteVector")
+	beego.Router("/api/get-vector-cache-stats", &controllers.ApiController{}, "GET:GetVectorCacheStats")
 
 	beego.Router("/api/get-global-chats", &controllers.ApiController{}, "GET:GetGlobalChats")
 	beego.Router("/api/get-chats", &controllers.ApiController{}, "GET:GetChats")
@@ -120,6 +124,24 @@ func initAPI() {
 	beego.Router("/api/update-task", &controllers.ApiController{}, "POST:UpdateTask")
 	beego.Router("/api/add-task", &controllers.ApiController{}, "POST:AddTask")
 	beego.Router("/api/delete-task", &controllers.ApiController{}, "POST:DeleteTask")
+	beego.Router("/api/upload-task-golden-set", &controllers.ApiController{}, "POST:UploadTaskGoldenSet")
+
+	beego.Router("/api/get-evaluations", &controllers.ApiController{}, "GET:GetEvaluations")
+	beego.Router("/api/get-evaluation", &controllers.ApiController{}, "GET:GetEvaluation")
+	beego.Router("/api/run-evaluation", &controllers.ApiController{}, "POST:RunEvaluation")
+	beego.Router("/api/delete-evaluation", &controllers.ApiController{}, "POST:DeleteEvaluation")
+
+	beego.Router("/api/get-qa-pairs", &controllers.ApiController{}, "GET:GetQaPairs")
+	beego.Router("/api/get-qa-pair", &controllers.ApiController{}, "GET:GetQaPair")
+	beego.Router("/api/update-qa-pair", &controllers.ApiController{}, "POST:UpdateQaPair")
+	beego.Router("/api/delete-qa-pair", &controllers.ApiController{}, "POST:DeleteQaPair")
+	beego.Router("/api/approve-qa-pairs", &controllers.ApiController{}, "POST:ApproveQaPairs")
+
+	beego.Router("/api/get-jobs", &controllers.ApiController{}, "GET:GetJobs")
+	beego.Router("/api/get-job", &controllers.ApiController{}, "GET:GetJob")
+	beego.Router("/api/cancel-job", &controllers.ApiController{}, "POST:CancelJob")
+	beego.Router("/api/resume-job", &controllers.ApiController{}, "POST:ResumeJob")
+	beego.Router("/api/delete-job", &controllers.ApiController{}, "POST:DeleteJob")
 
 	beego.Router("/api/get-global-articles", &controllers.ApiController{}, "GET:GetGlobalArticles")
 	beego.Router("/api/get-articles", &controllers.ApiController{}, "GET:GetArticles")
@@ -14,31 +14,25 @@
 
 package split
 
-import (
-	"strings"
+import "strings"
 
-	"github.com/casibase/casibase/model"
-)
-
-type BasicSplitProvider struct{}
+type BasicSplitProvider struct {
+	maxLength   int
+	countTokens TokenCounter
+}
 
-func NewBasicSplitProvider() (*BasicSplitProvider, error) {
-	return &BasicSplitProvider{}, nil
+func NewBasicSplitProvider(maxLength int, countTokens TokenCounter) (*BasicSplitProvider, error) {
+	return &BasicSplitProvider{maxLength: maxLength, countTokens: countTokens}, nil
 }
 
 func (p *BasicSplitProvider) SplitText(text string) ([]string, error) {
-	const maxLength = 210
 	res := []string{}
 	var temp string
 
 	lines := strings.Split(text, "\n")
 	for _, line := range lines {
-		tokenSize, err := model.GetTokenSize("gpt-3.5-turbo", temp+line)
-		if err != nil {
-			return nil, err
-		}
-
-		if tokenSize <= maxLength {
+		tokenSize := p.countTokens(temp + line)
+		if tokenSize <= p.maxLength {
 			if temp != "" {
 				temp += "\n"
 			}
@@ -17,18 +17,18 @@ package split
 import (
 	"regexp"
 	"strings"
-
-	"github.com/casibase/casibase/model"
 )
 
-type DefaultSplitProvider struct{}
+type DefaultSplitProvider struct {
+	maxLength   int
+	countTokens TokenCounter
+}
 
-func NewDefaultSplitProvider() (*DefaultSplitProvider, error) {
-	return &DefaultSplitProvider{}, nil
+func NewDefaultSplitProvider(maxLength int, countTokens TokenCounter) (*DefaultSplitProvider, error) {
+	return &DefaultSplitProvider{maxLength: maxLength, countTokens: countTokens}, nil
 }
 
 func (p *DefaultSplitProvider) SplitText(text string) ([]string, error) {
-	const maxLength = 210
 	sections := []string{}
 	var currentSection strings.Builder
 	var codeBlock strings.Builder
@@ -96,12 +96,8 @@ func (p *DefaultSplitProvider) SplitText(text string) ([]string, error) {
 			continue
 		}
 
-		tokenSize, err := model.GetTokenSize("gpt-3.5-turbo", currentSection.String()+line)
-		if err != nil {
-			return nil, err
-		}
-
-		if tokenSize <= maxLength {
+		tokenSize := p.countTokens(currentSection.String() + line)
+		if tokenSize <= p.maxLength {
 			if currentSection.Len() > 0 {
 				currentSection.WriteString("\n")
 			}
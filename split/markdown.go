@@ -0,0 +1,287 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package split
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	reMarkdownHeading  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	reMarkdownListItem = regexp.MustCompile(`^\s*([-*+]|\d+[.)])\s+`)
+	reMarkdownTableSep = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+)
+
+// MarkdownSplitProvider splits a markdown text by its sections, so that each chunk knows the
+// path of the headings it is under, like "Install > Linux > Proxy". The tables, lists and
+// code blocks of a section are kept in one chunk as long as they fit in it.
+type MarkdownSplitProvider struct {
+	chunkSize   int
+	countTokens TokenCounter
+	recursive   *RecursiveSplitProvider
+}
+
+type markdownBlock struct {
+	kind  string
+	lines []string
+}
+
+type markdownSection struct {
+	heading string
+	blocks  []*markdownBlock
+}
+
+func NewMarkdownSplitProvider(chunkSize int, countTokens TokenCounter) (*MarkdownSplitProvider, error) {
+	recursive, err := NewRecursiveSplitProvider(chunkSize, 0, countTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MarkdownSplitProvider{chunkSize: chunkSize, countTokens: countTokens, recursive: recursive}, nil
+}
+
+func (p *MarkdownSplitProvider) SplitText(text string) ([]string, error) {
+	sections, err := p.SplitSections(text)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []string{}
+	for _, section := range sections {
+		res = append(res, GetSectionText(section.Heading, section.Text))
+	}
+	return res, nil
+}
+
+func (p *MarkdownSplitProvider) SplitSections(text string) ([]*TextSection, error) {
+	res := []*TextSection{}
+	for _, section := range parseMarkdownSections(text) {
+		for _, chunk := range p.mergeBlocks(section.blocks) {
+			res = append(res, &TextSection{Text: chunk, Heading: section.heading})
+		}
+	}
+	return res, nil
+}
+
+func isMarkdownFence(line string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~")
+}
+
+func isMarkdownTableRow(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "|")
+}
+
+// parseMarkdownSections groups the lines of a text into paragraphs, tables, lists and code
+// blocks, under the path of their headings.
+func parseMarkdownSections(text string) []*markdownSection {
+	res := []*markdownSection{}
+	headings := make([]string, 6)
+	section := &markdownSection{}
+	var block *markdownBlock
+	isBlankInList := false
+
+	endBlock := func() {
+		if block != nil {
+			section.blocks = append(section.blocks, block)
+			block = nil
+		}
+		isBlankInList = false
+	}
+	startBlock := func(kind string, line string) {
+		endBlock()
+		block = &markdownBlock{kind: kind, lines: []string{line}}
+	}
+	endSection := func() {
+		endBlock()
+		if len(section.blocks) != 0 {
+			res = append(res, section)
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for _, line := range lines {
+		if block != nil && block.kind == "code" {
+			block.lines = append(block.lines, line)
+			if len(block.lines) > 1 && isMarkdownFence(line) {
+				endBlock()
+			}
+			continue
+		}
+
+		if isMarkdownFence(line) {
+			startBlock("code", line)
+			continue
+		}
+
+		if match := reMarkdownHeading.FindStringSubmatch(line); match != nil {
+			endSection()
+
+			level := len(match[1])
+			headings[level-1] = match[2]
+			for i := level; i < len(headings); i++ {
+				headings[i] = ""
+			}
+			section = &markdownSection{heading: getHeadingPath(headings)}
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			if block != nil && block.kind == "list" {
+				// a blank line between the items doesn't end the list
+				isBlankInList = true
+			} else {
+				endBlock()
+			}
+			continue
+		}
+
+		isListItem := reMarkdownListItem.MatchString(line)
+		isIndented := strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+		if isMarkdownTableRow(line) {
+			if block == nil || block.kind != "table" {
+				startBlock("table", line)
+				continue
+			}
+		} else if block != nil && block.kind == "list" && (isListItem || isIndented) {
+			if isBlankInList {
+				block.lines = append(block.lines, "")
+				isBlankInList = false
+			}
+		} else if isListItem {
+			startBlock("list", line)
+			continue
+		} else if block == nil || block.kind != "paragraph" {
+			startBlock("paragraph", line)
+			continue
+		}
+
+		block.lines = append(block.lines, line)
+	}
+	endSection()
+
+	return res
+}
+
+func getHeadingPath(headings []string) string {
+	path := []string{}
+	for _, heading := range headings {
+		if heading != "" {
+			path = append(path, heading)
+		}
+	}
+	return strings.Join(path, " > ")
+}
+
+// mergeBlocks packs the blocks of a section into chunks, a block too long for a chunk is split
+// into its table rows or list items first, and by the recursive splitter at last.
+func (p *MarkdownSplitProvider) mergeBlocks(blocks []*markdownBlock) []string {
+	res := []string{}
+	current := ""
+	for _, block := range blocks {
+		text := strings.Join(block.lines, "\n")
+		if current != "" && p.countTokens(current+"\n\n"+text) <= p.chunkSize {
+			current += "\n\n" + text
+			continue
+		}
+
+		if current != "" {
+			res = append(res, current)
+			current = ""
+		}
+
+		if p.countTokens(text) <= p.chunkSize {
+			current = text
+		} else {
+			res = append(res, p.splitBlock(block)...)
+		}
+	}
+
+	if current != "" {
+		res = append(res, current)
+	}
+	return res
+}
+
+func (p *MarkdownSplitProvider) splitBlock(block *markdownBlock) []string {
+	if block.kind == "table" {
+		// each chunk of a table repeats its header
+		header := ""
+		rows := block.lines
+		if len(rows) >= 2 && reMarkdownTableSep.MatchString(rows[1]) {
+			header = rows[0] + "\n" + rows[1]
+			rows = rows[2:]
+		}
+		return p.mergeParts(header, rows)
+	} else if block.kind == "list" {
+		items := []string{}
+		for _, line := range block.lines {
+			if len(items) == 0 || (reMarkdownListItem.MatchString(line) && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t")) {
+				items = append(items, line)
+			} else {
+				items[len(items)-1] += "\n" + line
+			}
+		}
+		return p.mergeParts("", items)
+	}
+
+	chunks, _ := p.recursive.SplitText(strings.Join(block.lines, "\n"))
+	return chunks
+}
+
+func (p *MarkdownSplitProvider) mergeParts(header string, parts []string) []string {
+	res := []string{}
+	current := ""
+	addChunk := func() {
+		if current == "" {
+			return
+		}
+		if header != "" {
+			current = header + "\n" + current
+		}
+		res = append(res, strings.TrimSpace(current))
+		current = ""
+	}
+
+	for _, part := range parts {
+		text := part
+		if current != "" {
+			text = current + "\n" + part
+		}
+		if header != "" {
+			text = header + "\n" + text
+		}
+
+		if p.countTokens(text) <= p.chunkSize {
+			if current != "" {
+				current += "\n"
+			}
+			current += part
+			continue
+		}
+
+		addChunk()
+		if p.countTokens(header+"\n"+part) <= p.chunkSize {
+			current = part
+		} else {
+			chunks, _ := p.recursive.SplitText(part)
+			res = append(res, chunks...)
+		}
+	}
+	addChunk()
+
+	return res
+}
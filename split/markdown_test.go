@@ -0,0 +1,110 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package split
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownSplit(t *testing.T) {
+	countTokens, err := GetTokenCounter("Character")
+	if err != nil {
+		panic(err)
+	}
+
+	p, err := NewMarkdownSplitProvider(80, countTokens)
+	if err != nil {
+		panic(err)
+	}
+
+	text := `# Install
+
+Download the package.
+
+## Linux
+
+### Proxy
+
+Set it to 30.
+
+- first step
+- second step
+
+  still the second step
+
+| Name | Value |
+| ---- | ----- |
+| a    | 1     |
+| b    | 2     |
+
+## Windows
+
+Run the installer.
+`
+	sections, err := p.SplitSections(text)
+	if err != nil {
+		panic(err)
+	}
+
+	type expectedSection struct {
+		heading string
+		prefix  string
+	}
+	expected := []expectedSection{
+		{"Install", "Download the package."},
+		{"Install > Linux > Proxy", "Set it to 30.\n\n- first step\n- second step\n\n  still the second step"},
+		{"Install > Linux > Proxy", "| Name | Value |"},
+		{"Install > Windows", "Run the installer."},
+	}
+	if len(sections) != len(expected) {
+		t.Fatalf("SplitSections() returned %d sections, expected %d: %v", len(sections), len(expected), sections)
+	}
+	for i, section := range sections {
+		if section.Heading != expected[i].heading || !strings.HasPrefix(section.Text, expected[i].prefix) {
+			t.Errorf("section %d = [%s] %q, expected [%s] %q", i, section.Heading, section.Text, expected[i].heading, expected[i].prefix)
+		}
+	}
+}
+
+func TestMarkdownSplitLargeTable(t *testing.T) {
+	countTokens, err := GetTokenCounter("Character")
+	if err != nil {
+		panic(err)
+	}
+
+	p, err := NewMarkdownSplitProvider(60, countTokens)
+	if err != nil {
+		panic(err)
+	}
+
+	text := "# Sizes\n\n| Name | Size |\n| --- | --- |\n| first | 1 |\n| second | 2 |\n| third | 3 |\n| fourth | 4 |\n\n```\n# not a heading\n```"
+	sections, err := p.SplitSections(text)
+	if err != nil {
+		panic(err)
+	}
+
+	if len(sections) != 3 {
+		t.Fatalf("SplitSections() returned %d sections, expected 3: %v", len(sections), sections)
+	}
+	for _, section := range sections[:2] {
+		if section.Heading != "Sizes" || !strings.HasPrefix(section.Text, "| Name | Size |\n| --- | --- |\n| ") || countTokens(section.Text) > 60 {
+			t.Errorf("table section = %q, expected the table header and at most 60 characters", section.Text)
+		}
+	}
+	if sections[2].Heading != "Sizes" || sections[2].Text != "```\n# not a heading\n```" {
+		t.Errorf("code section = [%s] %q, expected the code block under Sizes", sections[2].Heading, sections[2].Text)
+	}
+}
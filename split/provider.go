@@ -14,21 +14,108 @@
 
 package split
 
+import "fmt"
+
+const defaultChunkSize = 210
+
 type SplitProvider interface {
 	SplitText(text string) ([]string, error)
 }
 
-func GetSplitProvider(typ string) (SplitProvider, error) {
+// TextSection is a chunk of a text with the path of the headings it is under. A generated
+// question/answer pair has the answer as its text, and the section it is generated from as
+// its source. The rows of a spreadsheet have their sheet and row numbers. TokenCount, Price
+// and Currency are the usage of the providers that split it.
+type TextSection struct {
+	Text     string
+	Heading  string
+	Question string
+	Source   string
+	Sheet    string
+	StartRow int
+	EndRow   int
+
+	TokenCount int
+	Price      float64
+	Currency   string
+}
+
+// SectionSplitProvider is implemented by the split providers that know the structure of a text.
+type SectionSplitProvider interface {
+	SplitSections(text string) ([]*TextSection, error)
+}
+
+// GetTextSections splits a text into sections, the ones of the providers without structure
+// have no heading.
+func GetTextSections(p SplitProvider, text string) ([]*TextSection, error) {
+	if sectionSplitProvider, ok := p.(SectionSplitProvider); ok {
+		return sectionSplitProvider.SplitSections(text)
+	}
+
+	texts, err := p.SplitText(text)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []*TextSection{}
+	for _, text := range texts {
+		res = append(res, &TextSection{Text: text})
+	}
+	return res, nil
+}
+
+// GetSectionText puts the heading path in front of the text of a section.
+func GetSectionText(heading string, text string) string {
+	if heading == "" {
+		return text
+	}
+	return fmt.Sprintf("%s\n\n%s", heading, text)
+}
+
+// SplitConfig is the splitting configuration of a store. The chunk size and tokenizer
+// apply to all the types except "QA", the overlap only to "Recursive", the min chunk size
+// and the embedder only to "Semantic", and the generator only to "Generated QA".
+type SplitConfig struct {
+	Type         string
+	ChunkSize    int
+	ChunkOverlap int
+	MinChunkSize int
+	Tokenizer    string
+
+	EmbedTexts   TextEmbedder
+	GenerateText TextGenerator
+}
+
+func GetSplitProvider(config *SplitConfig) (SplitProvider, error) {
+	if config.Type == "QA" {
+		return NewQaSplitProvider()
+	}
+
+	chunkSize := config.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	countTokens, err := GetTokenCounter(config.Tokenizer)
+	if err != nil {
+		return nil, err
+	}
+
 	var p SplitProvider
-	var err error
-	if typ == "Default" {
-		p, err = NewDefaultSplitProvider()
-	} else if typ == "QA" {
-		p, err = NewQaSplitProvider()
-	} else if typ == "Basic" {
-		p, err = NewBasicSplitProvider()
+	if config.Type == "Default" {
+		p, err = NewDefaultSplitProvider(chunkSize, countTokens)
+	} else if config.Type == "Basic" {
+		p, err = NewBasicSplitProvider(chunkSize, countTokens)
+	} else if config.Type == "Recursive" {
+		p, err = NewRecursiveSplitProvider(chunkSize, config.ChunkOverlap, countTokens)
+	} else if config.Type == "Markdown" {
+		p, err = NewMarkdownSplitProvider(chunkSize, countTokens)
+	} else if config.Type == "Semantic" {
+		p, err = NewSemanticSplitProvider(chunkSize, config.MinChunkSize, countTokens, config.EmbedTexts)
+	} else if config.Type == "Generated QA" {
+		p, err = NewQaGenerationSplitProvider(chunkSize, countTokens, config.GenerateText)
 	} else {
-		p, err = NewDefaultSplitProvider()
+		p, err = NewDefaultSplitProvider(chunkSize, countTokens)
 	}
 
 	if err != nil {
@@ -31,7 +31,7 @@ import (
 func TestSplit(t *testing.T) {
 	object.InitConfig()
 
-	p, err := split.GetSplitProvider("Default")
+	p, err := split.GetSplitProvider(&split.SplitConfig{Type: "Default"})
 	if err != nil {
 		panic(err)
 	}
@@ -56,7 +56,7 @@ func TestSplit(t *testing.T) {
 func TestSplit2(t *testing.T) {
 	object.InitConfig()
 
-	p, err := split.GetSplitProvider("QA")
+	p, err := split.GetSplitProvider(&split.SplitConfig{Type: "QA"})
 	if err != nil {
 		panic(err)
 	}
@@ -86,7 +86,7 @@ func TestSplit2(t *testing.T) {
 func TestSplit3(t *testing.T) {
 	object.InitConfig()
 
-	p, err := split.GetSplitProvider("Default")
+	p, err := split.GetSplitProvider(&split.SplitConfig{Type: "Default"})
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,110 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package split
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/casibase/casibase/model"
+)
+
+const qaGenerationPrompt = "Generate question and answer pairs that cover the facts of the following document section, in the language of the section. Each answer should be understandable without the section. Output each pair as a line starting with \"Q:\" followed by a line starting with \"A:\", without other text.\n\nSection:\n%s"
+
+// TextGenerator answers a prompt with the model provider of the store.
+type TextGenerator func(prompt string) (string, *model.ModelResult, error)
+
+// QaGenerationSplitProvider splits a text into sections and asks the model to generate
+// question/answer pairs from each section. The pairs are the chunks: the question is the
+// text to embed, the answer is the knowledge and the section is kept as its source.
+type QaGenerationSplitProvider struct {
+	recursive    *RecursiveSplitProvider
+	generateText TextGenerator
+}
+
+func NewQaGenerationSplitProvider(chunkSize int, countTokens TokenCounter, generateText TextGenerator) (*QaGenerationSplitProvider, error) {
+	if generateText == nil {
+		return nil, fmt.Errorf("The generated QA split provider needs the model provider of the store")
+	}
+
+	recursive, err := NewRecursiveSplitProvider(chunkSize, 0, countTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QaGenerationSplitProvider{recursive: recursive, generateText: generateText}, nil
+}
+
+func (p *QaGenerationSplitProvider) SplitText(text string) ([]string, error) {
+	sections, err := p.SplitSections(text)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []string{}
+	for _, section := range sections {
+		res = append(res, fmt.Sprintf("Q: %s\nA: %s", section.Question, section.Text))
+	}
+	return res, nil
+}
+
+func (p *QaGenerationSplitProvider) SplitSections(text string) ([]*TextSection, error) {
+	chunks, err := p.recursive.SplitText(text)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []*TextSection{}
+	for _, chunk := range chunks {
+		answer, modelResult, err := p.generateText(fmt.Sprintf(qaGenerationPrompt, chunk))
+		if err != nil {
+			return nil, err
+		}
+
+		sections := parseGeneratedQaPairs(answer)
+		for _, section := range sections {
+			section.Source = chunk
+
+			// the usage of the request is shared by its pairs
+			if modelResult != nil {
+				section.TokenCount = modelResult.TotalTokenCount / len(sections)
+				section.Price = modelResult.TotalPrice / float64(len(sections))
+				section.Currency = modelResult.Currency
+			}
+		}
+		res = append(res, sections...)
+	}
+	return res, nil
+}
+
+// parseGeneratedQaPairs reads the pairs in the format of the QA split provider, the pairs
+// without a question or an answer are dropped.
+func parseGeneratedQaPairs(answer string) []*TextSection {
+	res := []*TextSection{}
+	pairs, _ := (&QaSplitProvider{}).SplitText(strings.ReplaceAll(answer, "\r\n", "\n"))
+	for _, pair := range pairs {
+		index := strings.Index(pair, "\nA:")
+		if index == -1 {
+			continue
+		}
+
+		question := strings.TrimSpace(strings.TrimPrefix(pair[:index], "Q:"))
+		text := strings.TrimSpace(pair[index+len("\nA:"):])
+		if question != "" && text != "" {
+			res = append(res, &TextSection{Question: question, Text: text})
+		}
+	}
+	return res
+}
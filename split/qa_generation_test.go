@@ -0,0 +1,73 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package split
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/casibase/casibase/model"
+)
+
+func TestParseGeneratedQaPairs(t *testing.T) {
+	answer := "Q: What is the timeout?\nA: 30 seconds.\nQ: Is a proxy needed?\nA: No.\nIt works without one.\nQ: A question without answer\n"
+	sections := parseGeneratedQaPairs(answer)
+	if len(sections) != 2 {
+		t.Fatalf("parseGeneratedQaPairs() returned %d pairs, expected 2: %v", len(sections), sections)
+	}
+	if sections[0].Question != "What is the timeout?" || sections[0].Text != "30 seconds." {
+		t.Errorf("pair 0 = %q / %q, expected the timeout pair", sections[0].Question, sections[0].Text)
+	}
+	if sections[1].Text != "No.\nIt works without one." {
+		t.Errorf("pair 1 answer = %q, expected the answer of two lines", sections[1].Text)
+	}
+}
+
+func TestQaGenerationSplit(t *testing.T) {
+	countTokens, err := GetTokenCounter("Character")
+	if err != nil {
+		panic(err)
+	}
+
+	prompts := []string{}
+	generateText := func(prompt string) (string, *model.ModelResult, error) {
+		prompts = append(prompts, prompt)
+		return "Q: What does the section say?\nA: It says something.\nQ: Anything else?\nA: Nothing else.", &model.ModelResult{TotalTokenCount: 10, TotalPrice: 0.2, Currency: "USD"}, nil
+	}
+
+	p, err := NewQaGenerationSplitProvider(30, countTokens, generateText)
+	if err != nil {
+		panic(err)
+	}
+
+	sections, err := p.SplitSections("The first section is here.\n\nThe second section is here.")
+	if err != nil {
+		panic(err)
+	}
+
+	if len(prompts) != 2 || len(sections) != 4 {
+		t.Fatalf("SplitSections() made %d requests and returned %d pairs, expected 2 and 4", len(prompts), len(sections))
+	}
+	if !strings.Contains(prompts[1], "The second section is here.") || sections[2].Source != "The second section is here." {
+		t.Errorf("pair 2 source = %q, expected the second section", sections[2].Source)
+	}
+	if sections[0].TokenCount != 5 || sections[0].Price != 0.1 || sections[0].Currency != "USD" {
+		t.Errorf("pair usage = %d tokens, %f %s, expected half of the request", sections[0].TokenCount, sections[0].Price, sections[0].Currency)
+	}
+
+	if _, err = NewQaGenerationSplitProvider(30, countTokens, nil); err == nil {
+		t.Errorf("NewQaGenerationSplitProvider() should fail without a model provider")
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package split
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// RecursiveSplitProvider splits a text by paragraphs, the paragraphs too long for a chunk
+// by lines, then by sentences, words and at last characters. The pieces are merged into
+// chunks of at most chunkSize tokens, each chunk starting with up to chunkOverlap tokens
+// of the end of the previous one.
+type RecursiveSplitProvider struct {
+	chunkSize    int
+	chunkOverlap int
+	countTokens  TokenCounter
+}
+
+var pieceSplitters = []func(text string) []string{
+	func(text string) []string { return splitAfter(text, "\n\n") },
+	func(text string) []string { return splitAfter(text, "\n") },
+	splitSentences,
+	func(text string) []string { return splitAfter(text, " ") },
+	splitCharacters,
+}
+
+func NewRecursiveSplitProvider(chunkSize int, chunkOverlap int, countTokens TokenCounter) (*RecursiveSplitProvider, error) {
+	if chunkSize <= 0 {
+		return nil, fmt.Errorf("The chunk size: %d should be positive", chunkSize)
+	}
+	if chunkOverlap < 0 || chunkOverlap >= chunkSize {
+		return nil, fmt.Errorf("The chunk overlap: %d should be between 0 and the chunk size: %d", chunkOverlap, chunkSize)
+	}
+
+	return &RecursiveSplitProvider{chunkSize: chunkSize, chunkOverlap: chunkOverlap, countTokens: countTokens}, nil
+}
+
+func (p *RecursiveSplitProvider) SplitText(text string) ([]string, error) {
+	pieces := p.splitPieces(text, 0)
+	return p.mergePieces(pieces), nil
+}
+
+// splitAfter splits the text after each separator, so that joining the pieces gives the text back.
+func splitAfter(text string, separator string) []string {
+	res := []string{}
+	for _, piece := range strings.SplitAfter(text, separator) {
+		if piece != "" {
+			res = append(res, piece)
+		}
+	}
+	return res
+}
+
+func isSentenceEnd(r rune) bool {
+	return r == '.' || r == '!' || r == '?' || r == ';'
+}
+
+func isCjkSentenceEnd(r rune) bool {
+	return r == '。' || r == '！' || r == '？' || r == '；'
+}
+
+// splitSentences splits the text after the sentence ends, keeping the following spaces with
+// the sentence. A Latin period only ends a sentence before a space, CJK ones always do.
+func splitSentences(text string) []string {
+	res := []string{}
+	runes := []rune(text)
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if isCjkSentenceEnd(r) || (isSentenceEnd(r) && i+1 < len(runes) && unicode.IsSpace(runes[i+1])) {
+			for i+1 < len(runes) && unicode.IsSpace(runes[i+1]) {
+				i++
+			}
+			res = append(res, string(runes[start:i+1]))
+			start = i + 1
+		}
+	}
+
+	if start < len(runes) {
+		res = append(res, string(runes[start:]))
+	}
+	return res
+}
+
+func splitCharacters(text string) []string {
+	res := []string{}
+	for _, r := range text {
+		res = append(res, string(r))
+	}
+	return res
+}
+
+// splitPieces splits the text with the splitter of the level, and the pieces still too long
+// for a chunk with the next levels.
+func (p *RecursiveSplitProvider) splitPieces(text string, level int) []string {
+	if level >= len(pieceSplitters) || p.countTokens(text) <= p.chunkSize {
+		return []string{text}
+	}
+
+	res := []string{}
+	for _, piece := range pieceSplitters[level](text) {
+		res = append(res, p.splitPieces(piece, level+1)...)
+	}
+	return res
+}
+
+func (p *RecursiveSplitProvider) mergePieces(pieces []string) []string {
+	res := []string{}
+	addChunk := func(window []string) {
+		chunk := strings.TrimSpace(strings.Join(window, ""))
+		if chunk != "" {
+			res = append(res, chunk)
+		}
+	}
+
+	window := []string{}
+	windowCounts := []int{}
+	total := 0
+	for _, piece := range pieces {
+		count := p.countTokens(piece)
+		if total+count > p.chunkSize && len(window) != 0 {
+			addChunk(window)
+
+			// keep the end of the chunk as the overlap of the next one
+			for len(window) != 0 && (total > p.chunkOverlap || total+count > p.chunkSize) {
+				total -= windowCounts[0]
+				window = window[1:]
+				windowCounts = windowCounts[1:]
+			}
+		}
+
+		window = append(window, piece)
+		windowCounts = append(windowCounts, count)
+		total += count
+	}
+
+	if len(window) != 0 {
+		addChunk(window)
+	}
+	return res
+}
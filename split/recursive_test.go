@@ -0,0 +1,87 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package split
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitSentences(t *testing.T) {
+	sentences := splitSentences("Version 1.2 is out. Is it stable? 是的。好")
+	expected := []string{"Version 1.2 is out. ", "Is it stable? ", "是的。", "好"}
+	if !reflect.DeepEqual(sentences, expected) {
+		t.Errorf("splitSentences() = %q, expected %q", sentences, expected)
+	}
+}
+
+func TestRecursiveSplit(t *testing.T) {
+	countTokens, err := GetTokenCounter("Character")
+	if err != nil {
+		panic(err)
+	}
+
+	p, err := NewRecursiveSplitProvider(30, 10, countTokens)
+	if err != nil {
+		panic(err)
+	}
+
+	text := "The first paragraph.\n\nThe second paragraph is a lot longer. It has two sentences.\n\nEnd."
+	chunks, err := p.SplitText(text)
+	if err != nil {
+		panic(err)
+	}
+
+	expected := []string{
+		"The first paragraph.\n\nThe",
+		"The second paragraph is a lot",
+		"is a lot longer.",
+		"longer. It has two sentences.",
+		"End.",
+	}
+	if !reflect.DeepEqual(chunks, expected) {
+		t.Errorf("SplitText() = %q, expected %q", chunks, expected)
+	}
+
+	for _, chunk := range chunks {
+		if countTokens(chunk) > 30 {
+			t.Errorf("chunk: %q is longer than the chunk size", chunk)
+		}
+	}
+
+	// a word longer than a chunk is split by characters
+	chunks, err = p.SplitText(strings.Repeat("x", 45))
+	if err != nil {
+		panic(err)
+	}
+	if len(chunks) != 2 || chunks[0] != strings.Repeat("x", 30) || chunks[1] != strings.Repeat("x", 25) {
+		t.Errorf("SplitText() = %q, expected chunks of 30 and 25 characters", chunks)
+	}
+}
+
+func TestNewRecursiveSplitProvider(t *testing.T) {
+	countTokens, err := GetTokenCounter("Character")
+	if err != nil {
+		panic(err)
+	}
+
+	if _, err = NewRecursiveSplitProvider(0, 0, countTokens); err == nil {
+		t.Errorf("NewRecursiveSplitProvider() should fail for a chunk size of 0")
+	}
+	if _, err = NewRecursiveSplitProvider(100, 100, countTokens); err == nil {
+		t.Errorf("NewRecursiveSplitProvider() should fail for an overlap as large as the chunk size")
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package split
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/casibase/casibase/embedding"
+)
+
+// semanticBreakpointPercentile is the percentile of the distances between adjacent sentences
+// above which a distance is a topic change. The percentile alone puts a breakpoint in any
+// long enough text, so a distance also has to reach semanticMinBreakpointDistance, which
+// keeps a text about a single topic in chunks cut only by the chunk size.
+const (
+	semanticBreakpointPercentile  = 95
+	semanticMinBreakpointDistance = 0.05
+)
+
+// TextEmbedder embeds a list of texts and returns the usage of each text.
+type TextEmbedder func(texts []string) ([][]float32, []*embedding.EmbeddingResult, error)
+
+// SemanticSplitProvider embeds the sentences of a text and starts a new chunk where the
+// similarity between adjacent sentences drops, so that a topic is not cut in half. The chunks
+// are at least minChunkSize tokens unless the text ends, and at most chunkSize tokens. The
+// usage of embedding the sentences is given on the sections.
+type SemanticSplitProvider struct {
+	chunkSize    int
+	minChunkSize int
+	countTokens  TokenCounter
+	embedTexts   TextEmbedder
+	recursive    *RecursiveSplitProvider
+}
+
+func NewSemanticSplitProvider(chunkSize int, minChunkSize int, countTokens TokenCounter, embedTexts TextEmbedder) (*SemanticSplitProvider, error) {
+	if embedTexts == nil {
+		return nil, fmt.Errorf("The semantic split provider needs the embedding provider of the store")
+	}
+	if minChunkSize < 0 || minChunkSize > chunkSize {
+		return nil, fmt.Errorf("The min chunk size: %d should be between 0 and the chunk size: %d", minChunkSize, chunkSize)
+	}
+
+	recursive, err := NewRecursiveSplitProvider(chunkSize, 0, countTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SemanticSplitProvider{chunkSize: chunkSize, minChunkSize: minChunkSize, countTokens: countTokens, embedTexts: embedTexts, recursive: recursive}, nil
+}
+
+func (p *SemanticSplitProvider) SplitText(text string) ([]string, error) {
+	sections, err := p.SplitSections(text)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []string{}
+	for _, section := range sections {
+		res = append(res, section.Text)
+	}
+	return res, nil
+}
+
+func (p *SemanticSplitProvider) SplitSections(text string) ([]*TextSection, error) {
+	sentences := p.getSentences(text)
+	if len(sentences) == 0 {
+		return []*TextSection{}, nil
+	}
+
+	data, embeddingResults, err := p.embedTexts(sentences)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != len(sentences) || len(embeddingResults) != len(sentences) {
+		return nil, fmt.Errorf("The embedding provider returned %d vectors for %d sentences", len(data), len(sentences))
+	}
+
+	distances := []float64{}
+	for i := 0; i+1 < len(data); i++ {
+		distances = append(distances, 1-getCosineSimilarity(data[i], data[i+1]))
+	}
+	threshold := getPercentile(distances, semanticBreakpointPercentile)
+
+	res := []*TextSection{}
+	section := &TextSection{}
+	for i, sentence := range sentences {
+		if section.Text != "" {
+			section.Text += " "
+		}
+		section.Text += sentence
+		addEmbeddingResult(section, embeddingResults[i])
+
+		if i+1 == len(sentences) {
+			break
+		}
+
+		size := p.countTokens(section.Text)
+		isBreakpoint := distances[i] > threshold && distances[i] >= semanticMinBreakpointDistance && size >= p.minChunkSize
+		isFull := p.countTokens(section.Text+" "+sentences[i+1]) > p.chunkSize
+		if isBreakpoint || isFull {
+			res = append(res, section)
+			section = &TextSection{}
+		}
+	}
+	res = append(res, section)
+
+	return res, nil
+}
+
+// getSentences splits the text into sentences, the sentences too long for a chunk are split
+// by the recursive splitter.
+func (p *SemanticSplitProvider) getSentences(text string) []string {
+	res := []string{}
+	for _, sentence := range splitSentences(text) {
+		sentence = strings.TrimSpace(sentence)
+		if sentence == "" {
+			continue
+		}
+
+		if p.countTokens(sentence) <= p.chunkSize {
+			res = append(res, sentence)
+		} else {
+			chunks, _ := p.recursive.SplitText(sentence)
+			res = append(res, chunks...)
+		}
+	}
+	return res
+}
+
+func addEmbeddingResult(section *TextSection, embeddingResult *embedding.EmbeddingResult) {
+	if embeddingResult == nil {
+		return
+	}
+
+	section.TokenCount += embeddingResult.TokenCount
+	section.Price += embeddingResult.Price
+	if section.Currency == "" {
+		section.Currency = embeddingResult.Currency
+	}
+}
+
+func getCosineSimilarity(a []float32, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+func getPercentile(values []float64, percentile float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	// linear interpolation between the closest ranks
+	rank := percentile / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
@@ -0,0 +1,114 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package split
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/casibase/casibase/embedding"
+)
+
+func TestSemanticSplit(t *testing.T) {
+	countTokens, err := GetTokenCounter("Character")
+	if err != nil {
+		panic(err)
+	}
+
+	// the sentences about cats and the ones about taxes point to different directions
+	embedTexts := func(texts []string) ([][]float32, []*embedding.EmbeddingResult, error) {
+		data := [][]float32{}
+		embeddingResults := []*embedding.EmbeddingResult{}
+		for _, text := range texts {
+			if strings.Contains(text, "cat") {
+				data = append(data, []float32{1, 0.1})
+			} else {
+				data = append(data, []float32{0.1, 1})
+			}
+			embeddingResults = append(embeddingResults, &embedding.EmbeddingResult{TokenCount: 2, Price: 0.5, Currency: "USD"})
+		}
+		return data, embeddingResults, nil
+	}
+
+	p, err := NewSemanticSplitProvider(100, 0, countTokens, embedTexts)
+	if err != nil {
+		panic(err)
+	}
+
+	text := "The cat sleeps. The cat eats. Taxes are due in April. Taxes can be paid online."
+	sections, err := p.SplitSections(text)
+	if err != nil {
+		panic(err)
+	}
+
+	if len(sections) != 2 || sections[0].Text != "The cat sleeps. The cat eats." || sections[1].Text != "Taxes are due in April. Taxes can be paid online." {
+		t.Fatalf("SplitSections() = %v, expected a chunk about cats and a chunk about taxes", sections)
+	}
+	if sections[0].TokenCount != 4 || sections[0].Price != 1 || sections[0].Currency != "USD" {
+		t.Errorf("section usage = %d tokens, %f %s, expected the usage of its 2 sentences", sections[0].TokenCount, sections[0].Price, sections[0].Currency)
+	}
+
+	// the min chunk size keeps the first topic with the second one
+	p, err = NewSemanticSplitProvider(100, 40, countTokens, embedTexts)
+	if err != nil {
+		panic(err)
+	}
+
+	sections, err = p.SplitSections(text)
+	if err != nil {
+		panic(err)
+	}
+	if len(sections) != 1 {
+		t.Errorf("SplitSections() returned %d sections, expected 1 with a min chunk size of 40", len(sections))
+	}
+
+	// the max chunk size cuts a topic longer than a chunk
+	p, err = NewSemanticSplitProvider(20, 0, countTokens, embedTexts)
+	if err != nil {
+		panic(err)
+	}
+
+	sections, err = p.SplitSections("The cat sleeps. The cat eats.")
+	if err != nil {
+		panic(err)
+	}
+	if len(sections) != 2 {
+		t.Errorf("SplitSections() returned %d sections, expected 2 with a chunk size of 20", len(sections))
+	}
+
+	// the sentences of a single topic only differ slightly, so there is no breakpoint
+	embedTexts = func(texts []string) ([][]float32, []*embedding.EmbeddingResult, error) {
+		data := [][]float32{}
+		embeddingResults := []*embedding.EmbeddingResult{}
+		for i := range texts {
+			data = append(data, []float32{1, float32(i%3) * 0.01})
+			embeddingResults = append(embeddingResults, &embedding.EmbeddingResult{})
+		}
+		return data, embeddingResults, nil
+	}
+
+	p, err = NewSemanticSplitProvider(1000, 0, countTokens, embedTexts)
+	if err != nil {
+		panic(err)
+	}
+
+	sections, err = p.SplitSections(strings.Repeat("The cat sleeps. ", 30))
+	if err != nil {
+		panic(err)
+	}
+	if len(sections) != 1 {
+		t.Errorf("SplitSections() returned %d sections, expected 1 for a single topic", len(sections))
+	}
+}
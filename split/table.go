@@ -0,0 +1,118 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package split
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/casibase/casibase/txt"
+)
+
+// TableSplitProvider renders each row of a table as "header: value" lines, and groups the
+// consecutive rows of a sheet into chunks of at most chunkSize tokens. The sheet and the row
+// numbers of each chunk are kept for the citations.
+type TableSplitProvider struct {
+	chunkSize   int
+	countTokens TokenCounter
+	recursive   *RecursiveSplitProvider
+}
+
+func NewTableSplitProvider(chunkSize int, countTokens TokenCounter) (*TableSplitProvider, error) {
+	recursive, err := NewRecursiveSplitProvider(chunkSize, 0, countTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TableSplitProvider{chunkSize: chunkSize, countTokens: countTokens, recursive: recursive}, nil
+}
+
+// GetTableSplitProvider returns the table split provider with the chunk size and tokenizer
+// of the config, the spreadsheets are split by it whatever the type of the config.
+func GetTableSplitProvider(config *SplitConfig) (*TableSplitProvider, error) {
+	chunkSize := config.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	countTokens, err := GetTokenCounter(config.Tokenizer)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTableSplitProvider(chunkSize, countTokens)
+}
+
+func getTableRowText(headers []string, row *txt.TableRow) string {
+	lines := []string{}
+	for i, value := range row.Values {
+		if value == "" {
+			continue
+		}
+
+		header := ""
+		if i < len(headers) {
+			header = headers[i]
+		}
+		if header == "" {
+			header = fmt.Sprintf("Column %d", i+1)
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", header, value))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (p *TableSplitProvider) SplitTables(tables []*txt.Table) ([]*TextSection, error) {
+	res := []*TextSection{}
+	for _, table := range tables {
+		var section *TextSection
+		for _, row := range table.Rows {
+			text := getTableRowText(table.Headers, row)
+			if text == "" {
+				continue
+			}
+
+			if section != nil && p.countTokens(section.Text+"\n\n"+text) <= p.chunkSize {
+				section.Text += "\n\n" + text
+				section.EndRow = row.Number
+				continue
+			}
+
+			if section != nil {
+				res = append(res, section)
+				section = nil
+			}
+
+			if p.countTokens(text) <= p.chunkSize {
+				section = &TextSection{Text: text, Heading: table.Sheet, Sheet: table.Sheet, StartRow: row.Number, EndRow: row.Number}
+				continue
+			}
+
+			// a row too long for a chunk is split, its chunks keep the row number
+			chunks, err := p.recursive.SplitText(text)
+			if err != nil {
+				return nil, err
+			}
+			for _, chunk := range chunks {
+				res = append(res, &TextSection{Text: chunk, Heading: table.Sheet, Sheet: table.Sheet, StartRow: row.Number, EndRow: row.Number})
+			}
+		}
+
+		if section != nil {
+			res = append(res, section)
+		}
+	}
+	return res, nil
+}
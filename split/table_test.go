@@ -0,0 +1,71 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package split
+
+import (
+	"testing"
+
+	"github.com/casibase/casibase/txt"
+)
+
+func TestTableSplit(t *testing.T) {
+	countTokens, err := GetTokenCounter("Character")
+	if err != nil {
+		panic(err)
+	}
+
+	p, err := NewTableSplitProvider(60, countTokens)
+	if err != nil {
+		panic(err)
+	}
+
+	tables := []*txt.Table{
+		{
+			Sheet:   "Prices",
+			Headers: []string{"Plan", "Price", ""},
+			Rows: []*txt.TableRow{
+				{Number: 2, Values: []string{"Basic", "10", ""}},
+				{Number: 3, Values: []string{"Pro", "20", "popular"}},
+				{Number: 5, Values: []string{"Enterprise", "contact us for a quote", ""}},
+			},
+		},
+		{
+			Sheet:   "Regions",
+			Headers: []string{"Region"},
+			Rows: []*txt.TableRow{
+				{Number: 2, Values: []string{"Europe"}},
+			},
+		},
+	}
+
+	sections, err := p.SplitTables(tables)
+	if err != nil {
+		panic(err)
+	}
+
+	expected := []TextSection{
+		{Text: "Plan: Basic\nPrice: 10\n\nPlan: Pro\nPrice: 20\nColumn 3: popular", Heading: "Prices", Sheet: "Prices", StartRow: 2, EndRow: 3},
+		{Text: "Plan: Enterprise\nPrice: contact us for a quote", Heading: "Prices", Sheet: "Prices", StartRow: 5, EndRow: 5},
+		{Text: "Region: Europe", Heading: "Regions", Sheet: "Regions", StartRow: 2, EndRow: 2},
+	}
+	if len(sections) != len(expected) {
+		t.Fatalf("SplitTables() returned %d sections, expected %d: %v", len(sections), len(expected), sections)
+	}
+	for i, section := range sections {
+		if *section != expected[i] {
+			t.Errorf("section %d = %+v, expected %+v", i, *section, expected[i])
+		}
+	}
+}
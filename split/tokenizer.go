@@ -0,0 +1,70 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package split
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkoukk/tiktoken-go"
+)
+
+const defaultTokenizer = "gpt-3.5-turbo"
+
+// TokenCounter returns the size of a text in the unit of a tokenizer.
+type TokenCounter func(text string) int
+
+func isTiktokenModel(name string) bool {
+	if _, ok := tiktoken.MODEL_TO_ENCODING[name]; ok {
+		return true
+	}
+
+	for prefix := range tiktoken.MODEL_PREFIX_TO_ENCODING {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTokenCounter returns the counter of a tokenizer, which is the name of a tiktoken
+// model like "gpt-4" or encoding like "cl100k_base", or "Character" to count the characters
+// for models without a tiktoken encoding.
+func GetTokenCounter(tokenizer string) (TokenCounter, error) {
+	if tokenizer == "" {
+		tokenizer = defaultTokenizer
+	}
+
+	if tokenizer == "Character" {
+		return func(text string) int {
+			return utf8.RuneCountInString(text)
+		}, nil
+	}
+
+	var tkm *tiktoken.Tiktoken
+	var err error
+	if isTiktokenModel(tokenizer) {
+		tkm, err = tiktoken.EncodingForModel(tokenizer)
+	} else {
+		tkm, err = tiktoken.GetEncoding(tokenizer)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return func(text string) int {
+		return len(tkm.Encode(text, nil, nil))
+	}, nil
+}
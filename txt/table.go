@@ -0,0 +1,145 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txt
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/tealeg/xlsx"
+)
+
+// Table is a sheet of a spreadsheet, the first non-empty row is its header. Sheet is empty
+// for a CSV file.
+type Table struct {
+	Sheet   string
+	Headers []string
+	Rows    []*TableRow
+}
+
+// TableRow is a row of a table, Number is its row number in the sheet starting from 1.
+type TableRow struct {
+	Number int
+	Values []string
+}
+
+func IsTableFileType(ext string) bool {
+	return ext == ".csv" || ext == ".xlsx"
+}
+
+func isEmptyRow(values []string) bool {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// addTableRow adds a row to the table, the first non-empty row is taken as the header.
+func addTableRow(table *Table, number int, values []string) {
+	if isEmptyRow(values) {
+		return
+	}
+
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+
+	if table.Headers == nil {
+		table.Headers = values
+	} else {
+		table.Rows = append(table.Rows, &TableRow{Number: number, Values: values})
+	}
+}
+
+func getTablesFromCsv(path string) ([]*Table, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+
+	table := &Table{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// the reader skips the empty lines, so the row number is the line of the record
+		number, _ := r.FieldPos(0)
+		addTableRow(table, number, record)
+	}
+
+	return []*Table{table}, nil
+}
+
+func getTablesFromXlsx(path string) ([]*Table, error) {
+	xlFile, err := xlsx.OpenFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []*Table{}
+	for _, sheet := range xlFile.Sheets {
+		table := &Table{Sheet: sheet.Name}
+		for i, row := range sheet.Rows {
+			values := []string{}
+			for _, cell := range row.Cells {
+				text, err := cell.FormattedValue()
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, text)
+			}
+
+			addTableRow(table, i+1, values)
+		}
+
+		if table.Headers != nil {
+			res = append(res, table)
+		}
+	}
+	return res, nil
+}
+
+// GetParsedTablesFromUrl reads the tables of a spreadsheet, every sheet of a workbook is
+// a table.
+func GetParsedTablesFromUrl(url string, ext string) ([]*Table, error) {
+	path, removeFile, err := getLocalFilePath(url)
+	if err != nil {
+		return nil, err
+	}
+	defer removeFile()
+
+	if ext == ".csv" {
+		return getTablesFromCsv(path)
+	} else if ext == ".xlsx" {
+		return getTablesFromXlsx(path)
+	} else {
+		return nil, fmt.Errorf("unsupported table file type: %s", ext)
+	}
+}
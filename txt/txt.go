@@ -21,26 +21,35 @@ import (
 )
 
 func GetSupportedFileTypes() []string {
-	return []string{".txt", ".md", ".csv", ".yaml", ".docx", ".pdf"}
+	return []string{".txt", ".md", ".csv", ".xlsx", ".yaml", ".docx", ".pdf"}
 }
 
-func GetParsedTextFromUrl(url string, ext string) (string, error) {
-	var path string
-	var err error
+// getLocalFilePath downloads the file of a URL to a temporary file, which is removed by the
+// returned function.
+func getLocalFilePath(url string) (string, func(), error) {
 	if !strings.HasPrefix(url, "http") {
-		path = url
-	} else {
-		path, err = getTempFilePathFromUrl(url)
+		return url, func() {}, nil
+	}
+
+	path, err := getTempFilePathFromUrl(url)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return path, func() {
+		err = os.Remove(path)
 		if err != nil {
-			return "", err
+			fmt.Printf("%v\n", err.Error())
 		}
-		defer func() {
-			err = os.Remove(path)
-			if err != nil {
-				fmt.Printf("%v\n", err.Error())
-			}
-		}()
+	}, nil
+}
+
+func GetParsedTextFromUrl(url string, ext string) (string, error) {
+	path, removeFile, err := getLocalFilePath(url)
+	if err != nil {
+		return "", err
 	}
+	defer removeFile()
 
 	var res string
 	if ext == "" || ext == ".txt" || ext == ".md" || ext == ".yaml" {
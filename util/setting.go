@@ -14,7 +14,10 @@
 
 package util
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+)
 
 func GetUploadXlsxPath(fileId string) string {
 	return fmt.Sprintf("tmpFiles/%s.xlsx", fileId)
@@ -27,3 +30,8 @@ func GetUploadCsvPath(fileId string) string {
 func GetUploadFilePath(fileId string) string {
 	return fmt.Sprintf("tmpFiles/%s", fileId)
 }
+
+// GetHnswIndexPath returns the file of an HNSW index, the name is escaped as it may contain "/".
+func GetHnswIndexPath(name string) string {
+	return fmt.Sprintf("tmpFiles/hnsw/%s.idx", url.PathEscape(name))
+}
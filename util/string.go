@@ -15,7 +15,9 @@
 package util
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -152,6 +154,11 @@ func GetRandomName() string {
 	return string(result)
 }
 
+func GetSha256Hash(s string) string {
+	hash := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(hash[:])
+}
+
 func GetId(owner, name string) string {
 	if strings.Contains(name, "/") {
 		return name
@@ -280,6 +280,38 @@ class ChatBox extends React.Component {
     }
   }
 
+  getCitationLocation(citation) {
+    if (!citation.startRow) {
+      return `#${citation.chunkIndex + 1}`;
+    }
+
+    const rows = citation.startRow === citation.endRow ? `${i18next.t("chat:Row")} ${citation.startRow}` : `${i18next.t("chat:Rows")} ${citation.startRow}-${citation.endRow}`;
+    return citation.sheet ? `(${citation.sheet}, ${rows})` : `(${rows})`;
+  }
+
+  renderCitations(message) {
+    if (message.author !== "AI" || !message.citations || message.citations.length === 0) {
+      return null;
+    }
+
+    return (
+      <div style={{fontSize: "12px", marginTop: "5px"}}>
+        <div>{i18next.t("chat:Sources")}</div>
+        {
+          message.citations.map((citation) => {
+            return (
+              <div key={citation.index}>
+                <a target="_blank" rel="noreferrer" href={citation.url}>
+                  {`[${citation.index}] ${citation.file} ${this.getCitationLocation(citation)}`}
+                </a>
+              </div>
+            );
+          })
+        }
+      </div>
+    );
+  }
+
   renderSuggestions(message) {
     if (message.author !== "AI" || !message.suggestions || !Array.isArray(message.suggestions)) {
       return null;
@@ -459,6 +491,7 @@ class ChatBox extends React.Component {
                     (message.author === "AI" && (this.props.disableInput === false || index !== messages.length - 1)) ? (
                       <Message.Footer>
                         <div>
+                          {this.renderCitations(message)}
                           {<Button className={"cs-button"} icon={<CopyOutlined />} style={{border: "none", color: ThemeDefault.colorPrimary}} onClick={() => this.copyMessageFromHTML(message.html.props.dangerouslySetInnerHTML.__html)}></Button>}
                           {index !== messages.length - 1 ? null : <Button className={"cs-button"} icon={<ReloadOutlined />} style={{border: "none", color: ThemeDefault.colorPrimary}} onClick={() => this.handleRegenerate()}></Button>}
                           {<Button className={"cs-button"} icon={message.likeUsers?.includes(this.props.account.name) ? <LikeFilled /> : <LikeOutlined />} style={{border: "none", color: ThemeDefault.colorPrimary}} onClick={() => this.handleMessageLike(message, "like")}></Button>}
@@ -256,6 +256,7 @@ class ChatPage extends BaseListPage {
           const lastMessage = res.data[res.data.length - 1];
           if (lastMessage.author === "AI" && lastMessage.replyTo !== "" && lastMessage.text === "") {
             let text = "";
+            let citations = [];
             this.setState({
               disableInput: true,
             });
@@ -282,6 +283,7 @@ class ChatPage extends BaseListPage {
               const lastMessage2 = Setting.deepCopy(lastMessage);
               text += jsonData.text;
               lastMessage2.text = Setting.parseAnswerAndSuggestions(text)["answer"];
+              lastMessage2.citations = citations;
               res.data[res.data.length - 1] = lastMessage2;
               res.data.map((message, index) => {
                 if (index === res.data.length - 1 && message.author === "AI") {
@@ -318,6 +320,7 @@ class ChatPage extends BaseListPage {
               const parseResult = Setting.parseAnswerAndSuggestions(text);
               lastMessage2.text = parseResult["answer"];
               lastMessage2.suggestions = parseResult["suggestions"];
+              lastMessage2.citations = citations;
 
               res.data[res.data.length - 1] = lastMessage2;
               res.data.map((message, index) => {
@@ -334,6 +337,8 @@ class ChatPage extends BaseListPage {
                   dots: "",
                 });
               }
+            }, (data) => {
+              citations = data;
             });
           } else {
             this.setState({
@@ -0,0 +1,184 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import {Button, Popconfirm, Table, Tag} from "antd";
+import i18next from "i18next";
+import React from "react";
+
+class EvaluationTable extends React.Component {
+  renderScore(score) {
+    return `${((score ?? 0) * 100).toFixed(1)}%`;
+  }
+
+  renderState(state) {
+    const color = state === "Finished" ? "success" : state === "Failed" ? "error" : "processing";
+    return <Tag color={color}>{state}</Tag>;
+  }
+
+  renderResults(evaluation) {
+    const columns = [
+      {
+        title: i18next.t("task:Question"),
+        dataIndex: "question",
+        key: "question",
+        width: "30%",
+      },
+      {
+        title: i18next.t("task:Rank"),
+        dataIndex: "rank",
+        key: "rank",
+        width: "70px",
+        render: (text, record, index) => {
+          return text === 0 ? "-" : text;
+        },
+      },
+      {
+        title: i18next.t("task:Retrieved files"),
+        dataIndex: "files",
+        key: "files",
+        width: "25%",
+        render: (text, record, index) => {
+          return (text ?? []).join(", ");
+        },
+      },
+      {
+        title: i18next.t("task:Answer"),
+        dataIndex: "answer",
+        key: "answer",
+        render: (text, record, index) => {
+          return record.error !== "" ? <span style={{color: "red"}}>{record.error}</span> : text;
+        },
+      },
+      {
+        title: i18next.t("task:Answer match"),
+        dataIndex: "answerScore",
+        key: "answerScore",
+        width: "110px",
+        render: (text, record, index) => {
+          return this.renderScore(text);
+        },
+      },
+    ];
+
+    return (
+      <Table rowKey={(record, index) => index} columns={columns} dataSource={evaluation.results ?? []} size="small" pagination={false} />
+    );
+  }
+
+  render() {
+    const columns = [
+      {
+        title: i18next.t("general:Created time"),
+        dataIndex: "createdTime",
+        key: "createdTime",
+        width: "160px",
+        render: (text, record, index) => {
+          return new Date(text).toLocaleString();
+        },
+      },
+      {
+        title: i18next.t("store:State"),
+        dataIndex: "state",
+        key: "state",
+        width: "100px",
+        render: (text, record, index) => {
+          return this.renderState(text);
+        },
+      },
+      {
+        title: i18next.t("task:Configuration"),
+        dataIndex: "config",
+        key: "config",
+        render: (text, record, index) => {
+          const config = record.config ?? {};
+          return `${config.embeddingProvider} / ${config.searchProvider} / ${config.rerankProvider || "-"} / ${config.retrievalMode || "Default"} / top ${config.topK}`;
+        },
+      },
+      {
+        title: i18next.t("task:Questions"),
+        dataIndex: "questionCount",
+        key: "questionCount",
+        width: "100px",
+        render: (text, record, index) => {
+          return `${(record.results ?? []).length} / ${text}`;
+        },
+      },
+      {
+        title: "Recall@k",
+        dataIndex: "recallAtK",
+        key: "recallAtK",
+        width: "100px",
+        render: (text, record, index) => {
+          return `${this.renderScore(text)} (k=${record.k})`;
+        },
+        sorter: (a, b) => a.recallAtK - b.recallAtK,
+      },
+      {
+        title: "MRR",
+        dataIndex: "mrr",
+        key: "mrr",
+        width: "90px",
+        render: (text, record, index) => {
+          return (text ?? 0).toFixed(3);
+        },
+        sorter: (a, b) => a.mrr - b.mrr,
+      },
+      {
+        title: i18next.t("task:Answer match"),
+        dataIndex: "answerMatch",
+        key: "answerMatch",
+        width: "110px",
+        render: (text, record, index) => {
+          return this.renderScore(text);
+        },
+        sorter: (a, b) => a.answerMatch - b.answerMatch,
+      },
+      {
+        title: i18next.t("chat:Price"),
+        dataIndex: "price",
+        key: "price",
+        width: "90px",
+        render: (text, record, index) => {
+          return `${(text ?? 0).toFixed(4)} ${record.currency}`;
+        },
+      },
+      {
+        title: i18next.t("general:Action"),
+        dataIndex: "action",
+        key: "action",
+        width: "90px",
+        render: (text, record, index) => {
+          return (
+            <Popconfirm
+              title={`${i18next.t("general:Sure to delete")}: ${record.name} ?`}
+              onConfirm={() => this.props.onDeleteEvaluation(record)}
+              okText={i18next.t("general:OK")}
+              cancelText={i18next.t("general:Cancel")}
+            >
+              <Button type="primary" danger>{i18next.t("general:Delete")}</Button>
+            </Popconfirm>
+          );
+        },
+      },
+    ];
+
+    return (
+      <Table rowKey="name" columns={columns} dataSource={this.props.evaluations ?? []} size="middle" bordered
+        expandable={{expandedRowRender: (record) => this.renderResults(record)}}
+        pagination={{pageSize: 10}} />
+    );
+  }
+}
+
+export default EvaluationTable;
@@ -57,7 +57,7 @@ class FileTree extends React.Component {
       newFolder: null,
       permissions: null,
       permissionMap: null,
-      searchValue: "",
+      searchValue: props.searchValue ?? "",
       isUploadFileModalVisible: false,
       uploadFileType: null,
       file: null,
@@ -321,7 +321,7 @@ class FileTree extends React.Component {
 
   renderSearch() {
     return (
-      <Search placeholder={i18next.t("store:Please input your search term")} onChange={(e) => {
+      <Search placeholder={i18next.t("store:Please input your search term")} defaultValue={this.state.searchValue} onChange={(e) => {
         this.setState({
           searchValue: e.target.value,
           selectedKeys: [],
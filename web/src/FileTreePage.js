@@ -51,6 +51,15 @@ class FileTreePage extends React.Component {
       });
   }
 
+  getSearchValue() {
+    // the sources of the answers link to their files by the file query parameter
+    const file = new URLSearchParams(this.props.location?.search).get("file");
+    if (file === null) {
+      return "";
+    }
+    return file.split("/").pop();
+  }
+
   render() {
     if (this.state.store === null) {
       return (
@@ -64,7 +73,7 @@ class FileTreePage extends React.Component {
       <div>
         <Row>
           <Col span={24}>
-            <FileTree account={this.props.account} store={this.state.store} onUpdateStore={(store) => {
+            <FileTree account={this.props.account} store={this.state.store} searchValue={this.getSearchValue()} onUpdateStore={(store) => {
               this.setState({
                 store: store,
               });
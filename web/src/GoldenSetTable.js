@@ -0,0 +1,55 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import {Table} from "antd";
+import i18next from "i18next";
+import React from "react";
+
+class GoldenSetTable extends React.Component {
+  render() {
+    const columns = [
+      {
+        title: i18next.t("task:Question"),
+        dataIndex: "question",
+        key: "question",
+        width: "30%",
+      },
+      {
+        title: i18next.t("task:Expected file"),
+        dataIndex: "expectedFile",
+        key: "expectedFile",
+        width: "15%",
+      },
+      {
+        title: i18next.t("task:Expected chunk"),
+        dataIndex: "expectedChunk",
+        key: "expectedChunk",
+        width: "25%",
+      },
+      {
+        title: i18next.t("task:Expected answer"),
+        dataIndex: "expectedAnswer",
+        key: "expectedAnswer",
+        width: "30%",
+      },
+    ];
+
+    return (
+      <Table rowKey={(record, index) => index} columns={columns} dataSource={this.props.goldenSet ?? []} size="middle" bordered
+        pagination={{pageSize: 10}} />
+    );
+  }
+}
+
+export default GoldenSetTable;
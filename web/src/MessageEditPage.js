@@ -238,6 +238,25 @@ class MessageEditPage extends React.Component {
             }} />
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}}>
+          <Col style={{marginTop: "5px"}} span={2}>
+            {i18next.t("message:Condensed question")}:
+          </Col>
+          <Col span={22}>
+            <TextArea disabled={true} autoSize={{minRows: 1, maxRows: 15}} value={this.state.message.condensedQuestion} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}}>
+          <Col style={{marginTop: "5px"}} span={2}>
+            {i18next.t("message:Retrieval queries")}:
+          </Col>
+          <Col span={22}>
+            <TextArea disabled={true} autoSize={{minRows: 1, maxRows: 15}} value={(this.state.message.retrievalQueries ?? []).join("\n")} />
+            <div style={{marginTop: "5px"}}>
+              {`${i18next.t("general:Tokens")}: ${this.state.message.retrievalTokenCount ?? 0}, ${i18next.t("chat:Price")}: ${this.state.message.retrievalPrice ?? 0}`}
+            </div>
+          </Col>
+        </Row>
         <Row style={{marginTop: "20px"}}>
           <Col style={{marginTop: "5px"}} span={2}>
             {i18next.t("message:Comment")}:
@@ -264,6 +283,14 @@ class MessageEditPage extends React.Component {
             }} />
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
+            {i18next.t("message:Is cached")}:
+          </Col>
+          <Col span={1} >
+            <Switch disabled={true} checked={this.state.message.isCached} />
+          </Col>
+        </Row>
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 19 : 2}>
             {i18next.t("general:Is deleted")}:
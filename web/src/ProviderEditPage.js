@@ -28,11 +28,48 @@ class ProviderEditPage extends React.Component {
       classes: props,
       providerName: props.match.params.providerName,
       provider: null,
+      embeddingCacheStats: null,
     };
   }
 
   UNSAFE_componentWillMount() {
     this.getProvider();
+    this.getEmbeddingCacheStats();
+  }
+
+  getEmbeddingCacheStats() {
+    ProviderBackend.getEmbeddingCacheStats()
+      .then((res) => {
+        if (res.status === "ok") {
+          this.setState({
+            embeddingCacheStats: res.data,
+          });
+        }
+      });
+  }
+
+  purgeEmbeddingCache() {
+    ProviderBackend.purgeEmbeddingCache(this.state.provider.owner, this.state.provider.name)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", `${i18next.t("provider:Embedding cache purged")}: ${res.data}`);
+          this.getEmbeddingCacheStats();
+        } else {
+          Setting.showMessage("error", `${i18next.t("provider:Failed to purge")}: ${res.msg}`);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `${i18next.t("provider:Failed to purge")}: ${error}`);
+      });
+  }
+
+  renderEmbeddingCacheStats() {
+    const stats = this.state.embeddingCacheStats?.providers?.find(item => item.provider === this.state.provider.name);
+    if (stats === undefined) {
+      return `${i18next.t("provider:Entries")}: 0`;
+    }
+
+    return `${i18next.t("provider:Entries")}: ${stats.entryCount}, ${i18next.t("provider:Hits")}: ${stats.hitCount}, ${i18next.t("provider:Tokens saved")}: ${stats.tokenCountSaved}, ${i18next.t("provider:Cost saved")}: ${stats.priceSaved} ${stats.currency}`;
   }
 
   getProvider() {
@@ -149,6 +186,9 @@ class ProviderEditPage extends React.Component {
               } else if (value === "Embedding") {
                 this.updateProviderField("type", "OpenAI");
                 this.updateProviderField("subType", "AdaSimilarity");
+              } else if (value === "Rerank") {
+                this.updateProviderField("type", "Cohere");
+                this.updateProviderField("subType", "rerank-multilingual-v3.0");
               }
             })}>
               {
@@ -156,6 +196,7 @@ class ProviderEditPage extends React.Component {
                   {id: "Storage", name: "Storage"},
                   {id: "Model", name: "Model"},
                   {id: "Embedding", name: "Embedding"},
+                  {id: "Rerank", name: "Rerank"},
                 ].map((item, index) => <Option key={index} value={item.id}>{item.name}</Option>)
               }
             </Select>
@@ -230,6 +271,14 @@ class ProviderEditPage extends React.Component {
                 } else if (value === "Dummy") {
                   this.updateProviderField("subType", "Dummy");
                 }
+              } else if (this.state.provider.category === "Rerank") {
+                if (value === "Cohere") {
+                  this.updateProviderField("subType", "rerank-multilingual-v3.0");
+                } else if (value === "Jina") {
+                  this.updateProviderField("subType", "jina-reranker-v2-base-multilingual");
+                } else if (value === "Local") {
+                  this.updateProviderField("subType", "custom-rerank");
+                }
               }
             })}>
               {
@@ -477,6 +526,41 @@ class ProviderEditPage extends React.Component {
             </>
           ) : null
         }
+        {
+          this.state.provider.category === "Embedding" ? (
+            <>
+              <Row style={{marginTop: "20px"}}>
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {i18next.t("provider:Requests per minute")}:
+                </Col>
+                <Col span={22} >
+                  <InputNumber min={0} value={this.state.provider.requestsPerMinute} onChange={value => {
+                    this.updateProviderField("requestsPerMinute", value);
+                  }} />
+                </Col>
+              </Row>
+              <Row style={{marginTop: "20px"}}>
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {i18next.t("provider:Tokens per minute")}:
+                </Col>
+                <Col span={22} >
+                  <InputNumber min={0} value={this.state.provider.tokensPerMinute} onChange={value => {
+                    this.updateProviderField("tokensPerMinute", value);
+                  }} />
+                </Col>
+              </Row>
+              <Row style={{marginTop: "20px"}}>
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {i18next.t("provider:Embedding cache")}:
+                </Col>
+                <Col span={22} >
+                  <span style={{marginRight: "10px"}}>{this.renderEmbeddingCacheStats()}</span>
+                  <Button danger onClick={() => this.purgeEmbeddingCache()}>{i18next.t("provider:Purge cache")}</Button>
+                </Col>
+              </Row>
+            </>
+          ) : null
+        }
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {this.state.provider.type === "Doubao" ? i18next.t("provider:EndpointID") : i18next.t("general:Provider URL")}:
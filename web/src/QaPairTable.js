@@ -0,0 +1,190 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import {Button, Input, Popconfirm, Select, Table} from "antd";
+import i18next from "i18next";
+import React from "react";
+import * as Setting from "./Setting";
+import * as QaPairBackend from "./backend/QaPairBackend";
+
+const {TextArea} = Input;
+
+class QaPairTable extends React.Component {
+  constructor(props) {
+    super(props);
+    this.state = {
+      qaPairs: [],
+      loading: false,
+    };
+  }
+
+  componentDidMount() {
+    this.getQaPairs();
+  }
+
+  getQaPairs() {
+    this.setState({loading: true});
+    QaPairBackend.getQaPairs(this.props.owner, this.props.store)
+      .then((res) => {
+        this.setState({loading: false});
+        if (res.status === "ok") {
+          this.setState({
+            qaPairs: res.data,
+          });
+        } else {
+          Setting.showMessage("error", `Failed to get QA pairs: ${res.msg}`);
+        }
+      });
+  }
+
+  updateQaPairField(index, field, value) {
+    const qaPairs = [...this.state.qaPairs];
+    qaPairs[index] = {...qaPairs[index], [field]: value};
+    this.setState({
+      qaPairs: qaPairs,
+    });
+  }
+
+  saveQaPair(qaPair) {
+    QaPairBackend.updateQaPair(qaPair.owner, qaPair.name, qaPair)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", "Successfully saved");
+        } else {
+          Setting.showMessage("error", `failed to save: ${res.msg}`);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `failed to save: ${error}`);
+      });
+  }
+
+  deleteQaPair(index) {
+    QaPairBackend.deleteQaPair(this.state.qaPairs[index])
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("general:Successfully deleted"));
+          this.setState({
+            qaPairs: Setting.deleteRow(this.state.qaPairs, index),
+          });
+        } else {
+          Setting.showMessage("error", `${i18next.t("general:Failed to delete")}: ${res.msg}`);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `${i18next.t("general:Failed to delete")}: ${error}`);
+      });
+  }
+
+  approveQaPairs() {
+    QaPairBackend.approveQaPairs(this.props.owner, this.props.store)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", `${i18next.t("store:Approved QA pairs")}: ${res.data}`);
+          this.getQaPairs();
+        } else {
+          Setting.showMessage("error", `failed to save: ${res.msg}`);
+        }
+      });
+  }
+
+  render() {
+    const columns = [
+      {
+        title: i18next.t("vector:File"),
+        dataIndex: "file",
+        key: "file",
+        width: "150px",
+        render: (text, record, index) => {
+          return `${text} #${record.index}`;
+        },
+      },
+      {
+        title: i18next.t("task:Question"),
+        dataIndex: "question",
+        key: "question",
+        width: "30%",
+        render: (text, record, index) => (
+          <TextArea autoSize={{minRows: 1, maxRows: 5}} value={text} onChange={e => this.updateQaPairField(index, "question", e.target.value)} />
+        ),
+      },
+      {
+        title: i18next.t("task:Answer"),
+        dataIndex: "answer",
+        key: "answer",
+        render: (text, record, index) => (
+          <TextArea autoSize={{minRows: 1, maxRows: 8}} value={text} onChange={e => this.updateQaPairField(index, "answer", e.target.value)} />
+        ),
+      },
+      {
+        title: i18next.t("store:State"),
+        dataIndex: "state",
+        key: "state",
+        width: "130px",
+        filters: ["Pending", "Approved", "Rejected"].map((state) => ({text: state, value: state})),
+        onFilter: (value, record) => record.state === value,
+        render: (text, record, index) => (
+          <Select virtual={false} style={{width: "100%"}} value={text} onChange={value => this.updateQaPairField(index, "state", value)}
+            options={["Pending", "Approved", "Rejected"].map((state) => Setting.getOption(state, state))} />
+        ),
+      },
+      {
+        title: i18next.t("chat:Price"),
+        dataIndex: "price",
+        key: "price",
+        width: "90px",
+        render: (text, record, index) => {
+          return `${(text ?? 0).toFixed(4)} ${record.currency}`;
+        },
+      },
+      {
+        title: i18next.t("general:Action"),
+        dataIndex: "action",
+        key: "action",
+        width: "160px",
+        render: (text, record, index) => {
+          return (
+            <div>
+              <Button style={{marginRight: "10px"}} type="primary" onClick={() => this.saveQaPair(record)}>{i18next.t("general:Save")}</Button>
+              <Popconfirm
+                title={`${i18next.t("general:Sure to delete")}: ${record.question} ?`}
+                onConfirm={() => this.deleteQaPair(index)}
+                okText={i18next.t("general:OK")}
+                cancelText={i18next.t("general:Cancel")}
+              >
+                <Button type="primary" danger>{i18next.t("general:Delete")}</Button>
+              </Popconfirm>
+            </div>
+          );
+        },
+      },
+    ];
+
+    return (
+      <Table rowKey="name" columns={columns} dataSource={this.state.qaPairs} size="middle" bordered loading={this.state.loading}
+        expandable={{expandedRowRender: (record) => <div style={{whiteSpace: "pre-wrap"}}>{record.source}</div>}}
+        pagination={{pageSize: 10}}
+        title={() => (
+          <div>
+            {i18next.t("store:QA pairs")}&nbsp;&nbsp;&nbsp;&nbsp;
+            <Button style={{marginRight: "5px"}} type="primary" size="small" onClick={() => this.approveQaPairs()}>{i18next.t("store:Approve all")}</Button>
+            <Button size="small" onClick={() => this.getQaPairs()}>{i18next.t("general:Refresh")}</Button>
+          </div>
+        )}
+      />
+    );
+  }
+}
+
+export default QaPairTable;
@@ -758,6 +758,14 @@ export function getProviderTypeOptions(category) {
         {id: "Dummy", name: "Dummy"},
       ]
     );
+  } else if (category === "Rerank") {
+    return (
+      [
+        {id: "Cohere", name: "Cohere"},
+        {id: "Jina", name: "Jina"},
+        {id: "Local", name: "Local"},
+      ]
+    );
   } else {
     return [];
   }
@@ -913,6 +921,13 @@ export function getProviderSubTypeOptions(category, type) {
           {id: "embed-english-v3.0", name: "embed-english-v3.0"},
         ]
       );
+    } else if (category === "Rerank") {
+      return (
+        [
+          {id: "rerank-english-v3.0", name: "rerank-english-v3.0"},
+          {id: "rerank-multilingual-v3.0", name: "rerank-multilingual-v3.0"},
+        ]
+      );
     }
   } else if (type === "iFlytek") {
     return (
@@ -956,6 +971,12 @@ export function getProviderSubTypeOptions(category, type) {
           {id: "custom-embedding", name: "custom-embedding"},
         ]
       );
+    } else if (category === "Rerank") {
+      return (
+        [
+          {id: "custom-rerank", name: "custom-rerank"},
+        ]
+      );
     } else {
       return [];
     }
@@ -1070,6 +1091,13 @@ export function getProviderSubTypeOptions(category, type) {
           {id: "jina-embeddings-v2-base-code", name: "jina-embeddings-v2-base-code"},
         ]
       );
+    } else if (category === "Rerank") {
+      return (
+        [
+          {id: "jina-reranker-v2-base-multilingual", name: "jina-reranker-v2-base-multilingual"},
+          {id: "jina-reranker-v1-base-en", name: "jina-reranker-v1-base-en"},
+        ]
+      );
     }
   } else if (type === "Mistral") {
     return ([
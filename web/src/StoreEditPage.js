@@ -13,15 +13,17 @@
 // limitations under the License.
 
 import React from "react";
-import {Button, Card, Col, Input, InputNumber, Row, Select} from "antd";
+import {Button, Card, Col, Input, InputNumber, Row, Select, Switch} from "antd";
 import * as StoreBackend from "./backend/StoreBackend";
 import * as StorageProviderBackend from "./backend/StorageProviderBackend";
 import * as ProviderBackend from "./backend/ProviderBackend";
+import * as JobBackend from "./backend/JobBackend";
 import * as Setting from "./Setting";
 import i18next from "i18next";
 import FileTree from "./FileTree";
 import {ThemeDefault} from "./Conf";
 import PromptTable from "./PromptTable";
+import QaPairTable from "./QaPairTable";
 import ProvidersUsageTable from "./ProvidersUsageTable";
 
 const {TextArea} = Input;
@@ -37,11 +39,19 @@ class StoreEditPage extends React.Component {
       storageProviders: [],
       modelProviders: [],
       embeddingProviders: [],
+      rerankProviders: [],
       store: null,
       themeColor: ThemeDefault.colorPrimary,
+      migrationEmbeddingProvider: "",
+      deleteOldVectors: false,
+      migrationJob: null,
     };
   }
 
+  componentWillUnmount() {
+    clearInterval(this.migrationTimer);
+  }
+
   UNSAFE_componentWillMount() {
     this.getStore();
     this.getStorageProviders();
@@ -65,6 +75,66 @@ class StoreEditPage extends React.Component {
       });
   }
 
+  migrateEmbeddingProvider() {
+    StoreBackend.migrateStoreEmbeddingProvider(this.state.store.owner, this.state.store.name, this.state.migrationEmbeddingProvider, this.state.deleteOldVectors)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("store:Migration job started"));
+          this.setState({
+            migrationJob: res.data,
+          });
+          this.migrationTimer = setInterval(() => this.updateMigrationJob(), 3000);
+        } else {
+          Setting.showMessage("error", `${i18next.t("store:Failed to migrate")}: ${res.msg}`);
+        }
+      })
+      .catch(error => {
+        Setting.showMessage("error", `${i18next.t("store:Failed to migrate")}: ${error}`);
+      });
+  }
+
+  updateMigrationJob() {
+    const job = this.state.migrationJob;
+    JobBackend.getJob(job.owner, job.name)
+      .then((res) => {
+        if (res.status !== "ok" || res.data === null) {
+          return;
+        }
+
+        const newJob = res.data;
+        this.setState({
+          migrationJob: newJob,
+        });
+
+        if (newJob.state === "Running") {
+          return;
+        }
+
+        clearInterval(this.migrationTimer);
+        if (newJob.state === "Finished") {
+          Setting.showMessage("success", `${i18next.t("store:Embedding provider migrated")}: ${i18next.t("general:Tokens")} ${newJob.tokenCount}, ${i18next.t("chat:Price")} ${newJob.price} ${newJob.currency}`);
+          this.setState({
+            store: {...this.state.store, embeddingProvider: newJob.embeddingProvider},
+          });
+        } else if (newJob.state === "Failed") {
+          Setting.showMessage("error", `${i18next.t("store:Failed to migrate")}: ${newJob.error}`);
+        }
+      });
+  }
+
+  renderMigrationJob() {
+    const job = this.state.migrationJob;
+    if (job === null) {
+      return null;
+    }
+
+    return (
+      <span style={{marginLeft: "10px"}}>
+        {`${i18next.t("store:State")}: ${job.state}, ${i18next.t("store:Files")}: ${job.finishedFileCount}/${job.fileCount}, ${i18next.t("general:Tokens")}: ${job.tokenCount}, ${i18next.t("chat:Price")}: ${job.price} ${job.currency ?? ""}`}
+      </span>
+    );
+  }
+
   getStorageProviders() {
     StorageProviderBackend.getStorageProviders(this.props.account.name)
       .then((res) => {
@@ -86,6 +156,7 @@ class StoreEditPage extends React.Component {
             storageProviders: res.data.filter(provider => provider.category === "Storage"),
             modelProviders: res.data.filter(provider => provider.category === "Model"),
             embeddingProviders: res.data.filter(provider => provider.category === "Embedding"),
+            rerankProviders: res.data.filter(provider => provider.category === "Rerank"),
           });
         } else {
           Setting.showMessage("error", `Failed to get providers: ${res.msg}`);
@@ -211,10 +282,76 @@ class StoreEditPage extends React.Component {
           </Col>
           <Col span={22} >
             <Select virtual={false} style={{width: "100%"}} value={this.state.store.splitProvider} onChange={(value => {this.updateStoreField("splitProvider", value);})}
-              options={[{name: "Default"}, {name: "Basic"}, {name: "QA"}].map((provider) => Setting.getOption(provider.name, provider.name))
+              options={[{name: "Default"}, {name: "Basic"}, {name: "Recursive"}, {name: "Markdown"}, {name: "Semantic"}, {name: "Generated QA"}, {name: "QA"}].map((provider) => Setting.getOption(provider.name, provider.name))
               } />
           </Col>
         </Row>
+        {
+          this.state.store.splitProvider === "QA" ? null : (
+            <React.Fragment>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {i18next.t("store:Chunk size")}:
+                </Col>
+                <Col span={22} >
+                  <InputNumber min={0} value={this.state.store.chunkSize} placeholder="210" onChange={value => {
+                    this.updateStoreField("chunkSize", value ?? 0);
+                  }} />
+                </Col>
+              </Row>
+              {
+                this.state.store.splitProvider !== "Semantic" ? null : (
+                  <Row style={{marginTop: "20px"}} >
+                    <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                      {i18next.t("store:Min chunk size")}:
+                    </Col>
+                    <Col span={22} >
+                      <InputNumber min={0} value={this.state.store.minChunkSize} onChange={value => {
+                        this.updateStoreField("minChunkSize", value ?? 0);
+                      }} />
+                    </Col>
+                  </Row>
+                )
+              }
+              {
+                this.state.store.splitProvider !== "Recursive" ? null : (
+                  <Row style={{marginTop: "20px"}} >
+                    <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                      {i18next.t("store:Chunk overlap")}:
+                    </Col>
+                    <Col span={22} >
+                      <InputNumber min={0} value={this.state.store.chunkOverlap} onChange={value => {
+                        this.updateStoreField("chunkOverlap", value ?? 0);
+                      }} />
+                    </Col>
+                  </Row>
+                )
+              }
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {i18next.t("store:Tokenizer")}:
+                </Col>
+                <Col span={22} >
+                  <Select virtual={false} allowClear style={{width: "100%"}} value={this.state.store.tokenizer === "" ? undefined : this.state.store.tokenizer} placeholder="gpt-3.5-turbo" onChange={(value => {this.updateStoreField("tokenizer", value ?? "");})}
+                    options={["gpt-3.5-turbo", "gpt-4", "cl100k_base", "o200k_base", "Character"].map((tokenizer) => Setting.getOption(tokenizer, tokenizer))
+                    } />
+                </Col>
+              </Row>
+              {
+                this.state.store.splitProvider !== "Generated QA" ? null : (
+                  <Row style={{marginTop: "20px"}} >
+                    <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                      {i18next.t("store:QA pairs")}:
+                    </Col>
+                    <Col span={22} >
+                      <QaPairTable owner={this.state.store.owner} store={this.state.store.name} />
+                    </Col>
+                  </Row>
+                )
+              }
+            </React.Fragment>
+          )
+        }
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {i18next.t("store:Model provider")}:
@@ -254,6 +391,44 @@ class StoreEditPage extends React.Component {
               } />
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {i18next.t("store:Migrate embedding provider")}:
+          </Col>
+          <Col span={8} >
+            <Select virtual={false} style={{width: "100%"}} value={this.state.migrationEmbeddingProvider} onChange={(value => {this.setState({migrationEmbeddingProvider: value});})}
+              options={this.state.embeddingProviders.filter(provider => provider.name !== this.state.store.embeddingProvider).map((provider) => Setting.getOption(`${provider.displayName} (${provider.name})`, provider.name))
+              } />
+          </Col>
+          <Col span={14} >
+            <span style={{marginLeft: "10px", marginRight: "10px"}}>{i18next.t("store:Delete old vectors")}:</span>
+            <Switch checked={this.state.deleteOldVectors} onChange={checked => {
+              this.setState({deleteOldVectors: checked});
+            }} />
+            <Button style={{marginLeft: "10px"}} disabled={this.state.migrationEmbeddingProvider === "" || this.state.migrationJob?.state === "Running"} onClick={() => this.migrateEmbeddingProvider()}>{i18next.t("store:Migrate")}</Button>
+            {this.renderMigrationJob()}
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {i18next.t("store:Embedding batch size")}:
+          </Col>
+          <Col span={22} >
+            <InputNumber min={0} max={2048} value={this.state.store.embeddingBatchSize} onChange={value => {
+              this.updateStoreField("embeddingBatchSize", value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {i18next.t("store:Embedding concurrency")}:
+          </Col>
+          <Col span={22} >
+            <InputNumber min={0} max={64} value={this.state.store.embeddingConcurrency} onChange={value => {
+              this.updateStoreField("embeddingConcurrency", value);
+            }} />
+          </Col>
+        </Row>
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {i18next.t("store:Embedding providers")}:
@@ -273,6 +448,280 @@ class StoreEditPage extends React.Component {
             <ProvidersUsageTable usageMap={this.state.store.embeddingUsageMap} />
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {i18next.t("store:Search provider")}:
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} style={{width: "100%"}} value={this.state.store.searchProvider === "" ? "Default" : this.state.store.searchProvider} onChange={(value => {this.updateStoreField("searchProvider", value);})}
+              options={[{name: "Default"}, {name: "Hnsw"}, {name: "Hybrid"}, {name: "Pgvector"}].map((provider) => Setting.getOption(provider.name, provider.name))
+              } />
+          </Col>
+        </Row>
+        {
+          this.state.store.searchProvider !== "Hnsw" ? null : (
+            <React.Fragment>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {i18next.t("store:HNSW M")}:
+                </Col>
+                <Col span={22} >
+                  <InputNumber min={0} max={128} value={this.state.store.hnswM} onChange={value => {
+                    this.updateStoreField("hnswM", value);
+                  }} />
+                </Col>
+              </Row>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {i18next.t("store:HNSW ef")}:
+                </Col>
+                <Col span={22} >
+                  <InputNumber min={0} max={1000} value={this.state.store.hnswEf} onChange={value => {
+                    this.updateStoreField("hnswEf", value);
+                  }} />
+                </Col>
+              </Row>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {i18next.t("store:Quantization")}:
+                </Col>
+                <Col span={22} >
+                  <Select virtual={false} style={{width: "100%"}} value={this.state.store.quantization} onChange={(value => {this.updateStoreField("quantization", value);})}
+                    options={[{id: "", name: "None"}, {id: "Int8", name: "Int8"}, {id: "Float16", name: "Float16"}].map((item) => Setting.getOption(item.name, item.id))
+                    } />
+                </Col>
+              </Row>
+            </React.Fragment>
+          )
+        }
+        {
+          this.state.store.searchProvider !== "Pgvector" ? null : (
+            <Row style={{marginTop: "20px"}} >
+              <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                {i18next.t("store:Pgvector index")}:
+              </Col>
+              <Col span={22} >
+                <Select virtual={false} style={{width: "100%"}} value={this.state.store.pgvectorIndex === "" ? "Hnsw" : this.state.store.pgvectorIndex} onChange={(value => {this.updateStoreField("pgvectorIndex", value);})}
+                  options={[{id: "Hnsw", name: "HNSW"}, {id: "IvfFlat", name: "IVFFlat"}].map((item) => Setting.getOption(item.name, item.id))
+                  } />
+              </Col>
+            </Row>
+          )
+        }
+        {
+          this.state.store.searchProvider !== "Hybrid" ? null : (
+            <React.Fragment>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {i18next.t("store:Vector weight")}:
+                </Col>
+                <Col span={22} >
+                  <InputNumber min={0} max={10} step={0.1} value={this.state.store.vectorWeight} onChange={value => {
+                    this.updateStoreField("vectorWeight", value);
+                  }} />
+                </Col>
+              </Row>
+              <Row style={{marginTop: "20px"}} >
+                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                  {i18next.t("store:Keyword weight")}:
+                </Col>
+                <Col span={22} >
+                  <InputNumber min={0} max={10} step={0.1} value={this.state.store.keywordWeight} onChange={value => {
+                    this.updateStoreField("keywordWeight", value);
+                  }} />
+                </Col>
+              </Row>
+            </React.Fragment>
+          )
+        }
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {i18next.t("store:Top K")}:
+          </Col>
+          <Col span={22} >
+            <InputNumber min={0} max={100} value={this.state.store.topK} onChange={value => {
+              this.updateStoreField("topK", value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {i18next.t("store:Min similarity")}:
+          </Col>
+          <Col span={22} >
+            <InputNumber min={0} max={1} step={0.01} value={this.state.store.minSimilarity} onChange={value => {
+              this.updateStoreField("minSimilarity", value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{}} span={(Setting.isMobile()) ? 22 : 2}>
+            {i18next.t("store:Use MMR")}:
+          </Col>
+          <Col span={22} style={{display: "flex", alignItems: "center"}}>
+            <input type="checkbox" checked={this.state.store.useMmr} onClick={(e) => {
+              this.updateStoreField("useMmr", e.target.checked);
+            }} />
+          </Col>
+        </Row>
+        {
+          !this.state.store.useMmr ? null : (
+            <Row style={{marginTop: "20px"}} >
+              <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                {i18next.t("store:MMR lambda")}:
+              </Col>
+              <Col span={22} >
+                <InputNumber min={0} max={1} step={0.1} value={this.state.store.mmrLambda} onChange={value => {
+                  this.updateStoreField("mmrLambda", value);
+                }} />
+              </Col>
+            </Row>
+          )
+        }
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{}} span={(Setting.isMobile()) ? 22 : 2}>
+            {i18next.t("store:Condense question")}:
+          </Col>
+          <Col span={22} style={{display: "flex", alignItems: "center"}}>
+            <input type="checkbox" checked={this.state.store.condenseQuestion} onClick={(e) => {
+              this.updateStoreField("condenseQuestion", e.target.checked);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {i18next.t("store:Retrieval mode")}:
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} style={{width: "100%"}} value={this.state.store.retrievalMode === "" ? "Default" : this.state.store.retrievalMode} onChange={(value => {this.updateStoreField("retrievalMode", value);})}
+              options={[
+                {value: "Default", label: "Default"},
+                {value: "Multi-query", label: "Multi-query"},
+                {value: "HyDE", label: "HyDE"},
+              ].map(item => Setting.getOption(item.label, item.value))} />
+          </Col>
+        </Row>
+        {
+          this.state.store.retrievalMode !== "Multi-query" ? null : (
+            <Row style={{marginTop: "20px"}} >
+              <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                {i18next.t("store:Query count")}:
+              </Col>
+              <Col span={22} >
+                <InputNumber min={0} max={10} value={this.state.store.retrievalQueryCount} onChange={value => {
+                  this.updateStoreField("retrievalQueryCount", value);
+                }} />
+              </Col>
+            </Row>
+          )
+        }
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {i18next.t("store:Enable answer cache")}:
+          </Col>
+          <Col span={22} style={{display: "flex", alignItems: "center"}}>
+            <input type="checkbox" checked={this.state.store.enableAnswerCache} onClick={(e) => {
+              this.updateStoreField("enableAnswerCache", e.target.checked);
+            }} />
+          </Col>
+        </Row>
+        {
+          !this.state.store.enableAnswerCache ? null : (
+            <Row style={{marginTop: "20px"}} >
+              <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                {i18next.t("store:Answer cache threshold")}:
+              </Col>
+              <Col span={22} >
+                <InputNumber min={0} max={1} step={0.01} value={this.state.store.answerCacheThreshold === 0 ? 0.95 : this.state.store.answerCacheThreshold} onChange={value => {
+                  this.updateStoreField("answerCacheThreshold", value);
+                }} />
+              </Col>
+            </Row>
+          )
+        }
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {i18next.t("store:Freshness")}:
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} style={{width: "100%"}} value={this.state.store.freshnessMode === "" ? "None" : this.state.store.freshnessMode} onChange={(value => {this.updateStoreField("freshnessMode", value);})}
+              options={[
+                {value: "None", label: "None"},
+                {value: "Time decay", label: "Time decay"},
+                {value: "Newest version", label: "Newest version"},
+              ].map(item => Setting.getOption(item.label, item.value))} />
+          </Col>
+        </Row>
+        {
+          this.state.store.freshnessMode !== "Time decay" ? null : (
+            <Row style={{marginTop: "20px"}} >
+              <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                {i18next.t("store:Half-life (days)")}:
+              </Col>
+              <Col span={4} >
+                <InputNumber min={0} value={this.state.store.freshnessHalfLife === 0 ? 180 : this.state.store.freshnessHalfLife} onChange={value => {
+                  this.updateStoreField("freshnessHalfLife", value);
+                }} />
+              </Col>
+              <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                {i18next.t("store:Decay weight")}:
+              </Col>
+              <Col span={4} >
+                <InputNumber min={0} max={1} step={0.1} value={this.state.store.freshnessWeight === 0 ? 0.5 : this.state.store.freshnessWeight} onChange={value => {
+                  this.updateStoreField("freshnessWeight", value);
+                }} />
+              </Col>
+            </Row>
+          )
+        }
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {i18next.t("store:Rerank provider")}:
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} allowClear style={{width: "100%"}} value={this.state.store.rerankProvider === "" ? undefined : this.state.store.rerankProvider} onChange={(value => {this.updateStoreField("rerankProvider", value ?? "");})}
+              options={this.state.rerankProviders.map((provider) => Setting.getOption(`${provider.displayName} (${provider.name})`, provider.name))
+              } />
+          </Col>
+        </Row>
+        {
+          !this.state.store.rerankProvider ? null : (
+            <Row style={{marginTop: "20px"}} >
+              <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                {i18next.t("store:Rerank count")}:
+              </Col>
+              <Col span={22} >
+                <InputNumber min={0} max={200} value={this.state.store.rerankCount} onChange={value => {
+                  this.updateStoreField("rerankCount", value);
+                }} />
+              </Col>
+            </Row>
+          )
+        }
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {i18next.t("store:Knowledge fallback")}:
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} style={{width: "100%"}} value={this.state.store.knowledgeFallback === "" ? "Answer" : this.state.store.knowledgeFallback} onChange={(value => {this.updateStoreField("knowledgeFallback", value);})}
+              options={[{name: "Answer"}, {name: "Reply"}].map((item) => Setting.getOption(i18next.t(`store:${item.name}`), item.name))
+              } />
+          </Col>
+        </Row>
+        {
+          this.state.store.knowledgeFallback !== "Reply" ? null : (
+            <Row style={{marginTop: "20px"}} >
+              <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                {i18next.t("store:Fallback reply")}:
+              </Col>
+              <Col span={22} >
+                <TextArea autoSize={{minRows: 1, maxRows: 5}} value={this.state.store.fallbackReply} onChange={(e) => {
+                  this.updateStoreField("fallbackReply", e.target.value);
+                }} />
+              </Col>
+            </Row>
+          )
+        }
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {i18next.t("store:Frequency")}:
@@ -20,6 +20,7 @@ import BaseListPage from "./BaseListPage";
 import * as Conf from "./Conf";
 import * as Setting from "./Setting";
 import * as StoreBackend from "./backend/StoreBackend";
+import * as JobBackend from "./backend/JobBackend";
 import i18next from "i18next";
 import {ThemeDefault} from "./Conf";
 
@@ -30,9 +31,13 @@ class StoreListPage extends BaseListPage {
     super(props);
     this.state = {
       ...this.state,
-      generating: false,
+      jobs: {},
     };
   }
+
+  componentWillUnmount() {
+    clearInterval(this.jobTimer);
+  }
   newStore() {
     const randomName = Setting.getRandomName();
     return {
@@ -101,22 +106,86 @@ class StoreListPage extends BaseListPage {
   }
 
   refreshStoreVectors(i) {
-    this.setState({generating: true});
     StoreBackend.refreshStoreVectors(this.state.data[i])
       .then((res) => {
         if (res.status === "ok") {
-          Setting.showMessage("success", "Vectors generated successfully");
+          this.setJob(res.data);
+          Setting.showMessage("success", i18next.t("store:Refresh job started"));
         } else {
           Setting.showMessage("error", `Vectors failed to generate: ${res.msg}`);
         }
-        this.setState({generating: false});
       })
       .catch(error => {
         Setting.showMessage("error", `Vectors failed to generate: ${error}`);
-        this.setState({generating: false});
       });
   }
 
+  setJob(job) {
+    this.setState({
+      jobs: {...this.state.jobs, [job.store]: job},
+    });
+
+    if (!this.jobTimer) {
+      this.jobTimer = setInterval(() => this.updateJobs(), 3000);
+    }
+  }
+
+  updateJobs() {
+    const runningJobs = Object.values(this.state.jobs).filter(job => job.state === "Running");
+    if (runningJobs.length === 0) {
+      clearInterval(this.jobTimer);
+      this.jobTimer = null;
+      return;
+    }
+
+    runningJobs.forEach(job => {
+      JobBackend.getJob(job.owner, job.name)
+        .then((res) => {
+          if (res.status !== "ok" || res.data === null) {
+            return;
+          }
+
+          const newJob = res.data;
+          this.setState({
+            jobs: {...this.state.jobs, [newJob.store]: newJob},
+          });
+
+          const summary = newJob.summary;
+          if (newJob.state === "Finished") {
+            Setting.showMessage("success", `${i18next.t("store:Vectors refreshed")}: ${i18next.t("store:Added")} ${summary.added}, ${i18next.t("store:Updated")} ${summary.updated}, ${i18next.t("store:Removed")} ${summary.removed}, ${i18next.t("store:Skipped")} ${summary.skipped}`);
+          } else if (newJob.state === "Failed") {
+            Setting.showMessage("error", `Vectors failed to generate: ${newJob.error}`);
+          }
+        });
+    });
+  }
+
+  cancelJob(job) {
+    JobBackend.cancelJob(job.owner, job.name)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("store:Refresh job canceled"));
+        } else {
+          Setting.showMessage("error", `${i18next.t("general:Failed to cancel")}: ${res.msg}`);
+        }
+      });
+  }
+
+  renderRefreshButton(record, index) {
+    const job = this.state.jobs[record.name];
+    if (job === undefined || job.state !== "Running") {
+      return (
+        <Button style={{marginBottom: "10px", marginRight: "10px"}} onClick={() => this.refreshStoreVectors(index)}>{i18next.t("store:Refresh Vectors")}</Button>
+      );
+    }
+
+    return (
+      <Button style={{marginBottom: "10px", marginRight: "10px"}} danger onClick={() => this.cancelJob(job)}>
+        {`${i18next.t("general:Cancel")} (${job.finishedFileCount}/${job.fileCount})`}
+      </Button>
+    );
+  }
+
   renderTable(stores) {
     const columns = [
       {
@@ -228,7 +297,7 @@ class StoreListPage extends BaseListPage {
               {
                 !Setting.isLocalAdminUser(this.props.account) ? null : (
                   <React.Fragment>
-                    <Button style={{marginBottom: "10px", marginRight: "10px"}} disabled={this.state.generating} onClick={() => this.refreshStoreVectors(index)}>{i18next.t("store:Refresh Vectors")}</Button>
+                    {this.renderRefreshButton(record, index)}
                     <Button style={{marginBottom: "10px", marginRight: "10px"}} type="primary" onClick={() => this.props.history.push(`/stores/${record.owner}/${record.name}`)}>{i18next.t("general:Edit")}</Button>
                     <Popconfirm
                       title={`${i18next.t("general:Sure to delete")}: ${record.name} ?`}
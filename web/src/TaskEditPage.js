@@ -13,14 +13,19 @@
 // limitations under the License.
 
 import React from "react";
-import {Button, Card, Col, Input, Row, Select} from "antd";
+import {Button, Card, Col, Input, Row, Select, Upload} from "antd";
+import {UploadOutlined} from "@ant-design/icons";
 import * as TaskBackend from "./backend/TaskBackend";
+import * as EvaluationBackend from "./backend/EvaluationBackend";
+import * as StoreBackend from "./backend/StoreBackend";
 import * as Setting from "./Setting";
 import i18next from "i18next";
 import * as ProviderBackend from "./backend/ProviderBackend";
 import * as MessageBackend from "./backend/MessageBackend";
 import ChatPage from "./ChatPage";
 import * as ConfTask from "./ConfTask";
+import GoldenSetTable from "./GoldenSetTable";
+import EvaluationTable from "./EvaluationTable";
 
 import {Controlled as CodeMirror} from "react-codemirror2";
 import "codemirror/lib/codemirror.css";
@@ -37,6 +42,8 @@ class TaskEditPage extends React.Component {
       classes: props,
       taskName: props.match.params.taskName,
       modelProviders: [],
+      stores: [],
+      evaluations: [],
       task: null,
       chatPageObj: null,
       loading: false,
@@ -46,6 +53,12 @@ class TaskEditPage extends React.Component {
   UNSAFE_componentWillMount() {
     this.getTask();
     this.getModelProviders();
+    this.getStores();
+    this.getEvaluations();
+  }
+
+  componentWillUnmount() {
+    clearTimeout(this.evaluationTimer);
   }
 
   getTask() {
@@ -97,6 +110,114 @@ class TaskEditPage extends React.Component {
       });
   }
 
+  getStores() {
+    StoreBackend.getStores("admin")
+      .then((res) => {
+        if (res.status === "ok") {
+          this.setState({
+            stores: res.data,
+          });
+        } else {
+          Setting.showMessage("error", `Failed to get stores: ${res.msg}`);
+        }
+      });
+  }
+
+  getEvaluations() {
+    EvaluationBackend.getEvaluations(this.props.account.name, this.state.taskName)
+      .then((res) => {
+        if (res.status === "ok") {
+          this.setState({
+            evaluations: res.data,
+          });
+
+          // poll the running evaluations until they are finished
+          clearTimeout(this.evaluationTimer);
+          if (res.data.some(evaluation => evaluation.state === "Running")) {
+            this.evaluationTimer = setTimeout(() => this.getEvaluations(), 3000);
+          }
+        } else {
+          Setting.showMessage("error", `Failed to get evaluations: ${res.msg}`);
+        }
+      });
+  }
+
+  runEvaluation() {
+    EvaluationBackend.runEvaluation(this.state.task.owner, this.state.taskName)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("task:Evaluation started"));
+          this.getEvaluations();
+        } else {
+          Setting.showMessage("error", `Failed to run evaluation: ${res.msg}`);
+        }
+      });
+  }
+
+  deleteEvaluation(evaluation) {
+    EvaluationBackend.deleteEvaluation(evaluation)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("general:Successfully deleted"));
+          this.getEvaluations();
+        } else {
+          Setting.showMessage("error", `${i18next.t("general:Failed to delete")}: ${res.msg}`);
+        }
+      });
+  }
+
+  uploadGoldenSet(file) {
+    TaskBackend.uploadTaskGoldenSet(this.state.task.owner, this.state.taskName, file)
+      .then((res) => {
+        if (res.status === "ok") {
+          Setting.showMessage("success", i18next.t("task:Golden set uploaded"));
+          this.updateTaskField("goldenSet", res.data);
+        } else {
+          Setting.showMessage("error", `Failed to upload golden set: ${res.msg}`);
+        }
+      });
+  }
+
+  renderEvaluation() {
+    return (
+      <React.Fragment>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {i18next.t("chat:Store")}:
+          </Col>
+          <Col span={22} >
+            <Select virtual={false} style={{width: "100%"}} value={this.state.task.store} onChange={(value => {this.updateTaskField("store", value);})}
+              options={this.state.stores.map((store) => Setting.getOption(`${store.displayName} (${store.name})`, `${store.name}`))
+              } />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {i18next.t("task:Golden set")}:
+          </Col>
+          <Col span={22} >
+            <Upload accept=".csv,.json" showUploadList={false} beforeUpload={(file) => {
+              this.uploadGoldenSet(file);
+              return false;
+            }}>
+              <Button style={{marginBottom: "10px"}} icon={<UploadOutlined />}>{i18next.t("store:Upload file")}</Button>
+            </Upload>
+            <GoldenSetTable goldenSet={this.state.task.goldenSet} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {i18next.t("task:Evaluations")}:
+          </Col>
+          <Col span={22} >
+            <Button disabled={!this.state.task.store || (this.state.task.goldenSet ?? []).length === 0} style={{marginBottom: "10px", width: "100px"}} type="primary" onClick={() => this.runEvaluation()}>{i18next.t("general:Run")}</Button>
+            <EvaluationTable evaluations={this.state.evaluations} onDeleteEvaluation={(evaluation) => this.deleteEvaluation(evaluation)} />
+          </Col>
+        </Row>
+      </React.Fragment>
+    );
+  }
+
   parseTaskField(key, value) {
     if ([""].includes(key)) {
       value = Setting.myParseInt(value);
@@ -225,13 +346,17 @@ class TaskEditPage extends React.Component {
                 [
                   {id: "Labeling", name: "Labeling"},
                   {id: "PBL", name: "PBL"},
+                  {id: "Evaluation", name: "Evaluation"},
                 ].map((item, index) => <Option key={index} value={item.id}>{item.name}</Option>)
               }
             </Select>
           </Col>
         </Row>
         {
-          this.state.task.type === "Labeling" ? null : (
+          this.state.task.type === "Evaluation" ? this.renderEvaluation() : null
+        }
+        {
+          (this.state.task.type === "Labeling" || this.state.task.type === "Evaluation") ? null : (
             <React.Fragment>
               <Row style={{marginTop: "20px"}} >
                 <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
@@ -294,81 +419,87 @@ class TaskEditPage extends React.Component {
             </React.Fragment>
           )
         }
-        <Row style={{marginTop: "20px"}} >
-          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
-            {i18next.t("task:Text")}:
-          </Col>
-          <Col span={22} >
-            <TextArea autoSize={{minRows: 1, maxRows: 15}} value={this.state.task.text} onChange={(e) => {
-              this.updateTaskField("text", e.target.value);
-            }} />
-          </Col>
-        </Row>
         {
-          (this.state.task.type !== "Labeling") ? null : (
+          this.state.task.type === "Evaluation" ? null : (
             <React.Fragment>
-              <Row style={{marginTop: "20px"}} >
-                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
-                  {i18next.t("task:Example")}:
-                </Col>
-                <Col span={22} >
-                  <Input value={this.state.task.example} onChange={e => {
-                    this.updateTaskField("example", e.target.value);
-                  }} />
-                </Col>
-              </Row>
-              <Row style={{marginTop: "20px"}} >
-                <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
-                  {i18next.t("task:Labels")}:
-                </Col>
-                <Col span={22} >
-                  <Select virtual={false} mode="tags" style={{width: "100%"}} value={this.state.task.labels} onChange={(value => {this.updateTaskField("labels", value);})}>
-                    {
-                      this.state.task.labels?.map((item, index) => <Option key={index} value={item}>{item}</Option>)
-                    }
-                  </Select>
-                </Col>
-              </Row>
-            </React.Fragment>
-          )
-        }
-        <Row style={{marginTop: "20px"}} >
-          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
-            {i18next.t("task:Question")}:
-          </Col>
-          <Col span={22} >
-            <TextArea disabled={true} autoSize={{minRows: 1, maxRows: 15}} value={(this.state.task.type !== "Labeling") ? this.getProjectText() : this.getQuestion()} onChange={(e) => {}} />
-          </Col>
-        </Row>
-        {
-          (this.state.task.type !== "Labeling") ? (
             <Row style={{marginTop: "20px"}} >
               <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
-                {i18next.t("general:Chat")}:
+                {i18next.t("task:Text")}:
               </Col>
               <Col span={22} >
-                <Button disabled={this.state.task.subject === "" || this.state.task.topic === "" || this.state.task.result === "" || this.state.task.activity === "" || this.state.task.grade === ""} style={{marginBottom: "20px", width: "200px"}} type="primary" onClick={() => this.generateProject()}>{i18next.t("task:Generate Project")}</Button>
-                <ChatPage onCreateChatPage={(chatPageObj) => {this.setState({chatPageObj: chatPageObj});}} account={this.props.account} />
+                <TextArea autoSize={{minRows: 1, maxRows: 15}} value={this.state.task.text} onChange={(e) => {
+                  this.updateTaskField("text", e.target.value);
+                }} />
               </Col>
             </Row>
-          ) : (
+            {
+              (this.state.task.type !== "Labeling") ? null : (
+                <React.Fragment>
+                  <Row style={{marginTop: "20px"}} >
+                    <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                      {i18next.t("task:Example")}:
+                    </Col>
+                    <Col span={22} >
+                      <Input value={this.state.task.example} onChange={e => {
+                        this.updateTaskField("example", e.target.value);
+                      }} />
+                    </Col>
+                  </Row>
+                  <Row style={{marginTop: "20px"}} >
+                    <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                      {i18next.t("task:Labels")}:
+                    </Col>
+                    <Col span={22} >
+                      <Select virtual={false} mode="tags" style={{width: "100%"}} value={this.state.task.labels} onChange={(value => {this.updateTaskField("labels", value);})}>
+                        {
+                          this.state.task.labels?.map((item, index) => <Option key={index} value={item}>{item}</Option>)
+                        }
+                      </Select>
+                    </Col>
+                  </Row>
+                </React.Fragment>
+              )
+            }
             <Row style={{marginTop: "20px"}} >
               <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
-                {i18next.t("task:Log")}:
+                {i18next.t("task:Question")}:
               </Col>
               <Col span={22} >
-                <Button loading={this.state.loading} style={{marginBottom: "20px", width: "100px"}} type="primary" onClick={this.runTask.bind(this)}>{i18next.t("general:Run")}</Button>
-                <div style={{height: "200px"}}>
-                  <CodeMirror
-                    value={this.state.task.log}
-                    options={{mode: "javascript", theme: "material-darker"}}
-                    onBeforeChange={(editor, data, value) => {
-                      this.updateTaskField("log", value);
-                    }}
-                  />
-                </div>
+                <TextArea disabled={true} autoSize={{minRows: 1, maxRows: 15}} value={(this.state.task.type !== "Labeling") ? this.getProjectText() : this.getQuestion()} onChange={(e) => {}} />
               </Col>
             </Row>
+            {
+              (this.state.task.type !== "Labeling") ? (
+                <Row style={{marginTop: "20px"}} >
+                  <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                    {i18next.t("general:Chat")}:
+                  </Col>
+                  <Col span={22} >
+                    <Button disabled={this.state.task.subject === "" || this.state.task.topic === "" || this.state.task.result === "" || this.state.task.activity === "" || this.state.task.grade === ""} style={{marginBottom: "20px", width: "200px"}} type="primary" onClick={() => this.generateProject()}>{i18next.t("task:Generate Project")}</Button>
+                    <ChatPage onCreateChatPage={(chatPageObj) => {this.setState({chatPageObj: chatPageObj});}} account={this.props.account} />
+                  </Col>
+                </Row>
+              ) : (
+                <Row style={{marginTop: "20px"}} >
+                  <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+                    {i18next.t("task:Log")}:
+                  </Col>
+                  <Col span={22} >
+                    <Button loading={this.state.loading} style={{marginBottom: "20px", width: "100px"}} type="primary" onClick={this.runTask.bind(this)}>{i18next.t("general:Run")}</Button>
+                    <div style={{height: "200px"}}>
+                      <CodeMirror
+                        value={this.state.task.log}
+                        options={{mode: "javascript", theme: "material-darker"}}
+                        onBeforeChange={(editor, data, value) => {
+                          this.updateTaskField("log", value);
+                        }}
+                      />
+                    </div>
+                  </Col>
+                </Row>
+              )
+            }
+            </React.Fragment>
           )
         }
       </Card>
@@ -128,6 +128,26 @@ class VectorEditPage extends React.Component {
             }} />
           </Col>
         </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {i18next.t("vector:Heading")}:
+          </Col>
+          <Col span={22} >
+            <Input value={this.state.vector.heading} onChange={e => {
+              this.updateVectorField("heading", e.target.value);
+            }} />
+          </Col>
+        </Row>
+        <Row style={{marginTop: "20px"}} >
+          <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
+            {i18next.t("vector:Question")}:
+          </Col>
+          <Col span={22} >
+            <Input value={this.state.vector.question} onChange={e => {
+              this.updateVectorField("question", e.target.value);
+            }} />
+          </Col>
+        </Row>
         <Row style={{marginTop: "20px"}} >
           <Col style={{marginTop: "5px"}} span={(Setting.isMobile()) ? 22 : 2}>
             {i18next.t("vector:Text")}:
@@ -153,6 +153,13 @@ class VectorListPage extends BaseListPage {
         width: "80px",
         sorter: (a, b) => a.index - b.index,
       },
+      {
+        title: i18next.t("vector:Heading"),
+        dataIndex: "heading",
+        key: "heading",
+        width: "150px",
+        sorter: (a, b) => a.heading.localeCompare(b.heading),
+      },
       {
         title: i18next.t("vector:Text"),
         dataIndex: "text",
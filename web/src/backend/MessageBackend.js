@@ -46,7 +46,7 @@ export function getChatMessages(owner, chat) {
 
 const eventSourceMap = new Map();
 
-export function getMessageAnswer(owner, name, onMessage, onError, onEnd) {
+export function getMessageAnswer(owner, name, onMessage, onError, onEnd, onSources) {
   if (eventSourceMap.has(`${owner}/${name}`)) {
     return;
   }
@@ -59,6 +59,12 @@ export function getMessageAnswer(owner, name, onMessage, onError, onEnd) {
     onMessage(e.data);
   });
 
+  eventSource.addEventListener("sources", (e) => {
+    if (onSources) {
+      onSources(JSON.parse(e.data));
+    }
+  });
+
   eventSource.addEventListener("myerror", (e) => {
     onError(e.data);
     eventSource.close();
@@ -79,3 +79,23 @@ export function deleteProvider(provider) {
     body: JSON.stringify(newProvider),
   }).then(res => res.json());
 }
+
+export function getEmbeddingCacheStats() {
+  return fetch(`${Setting.ServerUrl}/api/get-embedding-cache-stats`, {
+    method: "GET",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function purgeEmbeddingCache(owner, name) {
+  return fetch(`${Setting.ServerUrl}/api/purge-embedding-cache?id=${owner}/${encodeURIComponent(name)}`, {
+    method: "POST",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
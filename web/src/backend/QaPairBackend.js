@@ -0,0 +1,69 @@
+// Copyright 2024 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+import * as Setting from "../Setting";
+
+export function getQaPairs(owner, store) {
+  return fetch(`${Setting.ServerUrl}/api/get-qa-pairs?owner=${owner}&store=${encodeURIComponent(store)}`, {
+    method: "GET",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function getQaPair(owner, name) {
+  return fetch(`${Setting.ServerUrl}/api/get-qa-pair?id=${owner}/${encodeURIComponent(name)}`, {
+    method: "GET",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
+
+export function updateQaPair(owner, name, qaPair) {
+  const newQaPair = Setting.deepCopy(qaPair);
+  return fetch(`${Setting.ServerUrl}/api/update-qa-pair?id=${owner}/${encodeURIComponent(name)}`, {
+    method: "POST",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+    body: JSON.stringify(newQaPair),
+  }).then(res => res.json());
+}
+
+export function deleteQaPair(qaPair) {
+  const newQaPair = Setting.deepCopy(qaPair);
+  return fetch(`${Setting.ServerUrl}/api/delete-qa-pair`, {
+    method: "POST",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+    body: JSON.stringify(newQaPair),
+  }).then(res => res.json());
+}
+
+export function approveQaPairs(owner, store) {
+  return fetch(`${Setting.ServerUrl}/api/approve-qa-pairs?owner=${owner}&store=${encodeURIComponent(store)}`, {
+    method: "POST",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
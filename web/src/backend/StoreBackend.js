@@ -91,3 +91,13 @@ export function refreshStoreVectors(store) {
     body: JSON.stringify(newStore),
   }).then(res => res.json());
 }
+
+export function migrateStoreEmbeddingProvider(owner, name, embeddingProvider, deleteOldVectors) {
+  return fetch(`${Setting.ServerUrl}/api/migrate-store-embedding-provider?id=${owner}/${encodeURIComponent(name)}&embeddingProvider=${encodeURIComponent(embeddingProvider)}&deleteOldVectors=${deleteOldVectors}`, {
+    method: "POST",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+  }).then(res => res.json());
+}
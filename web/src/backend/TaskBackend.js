@@ -79,3 +79,16 @@ export function deleteTask(task) {
     body: JSON.stringify(newTask),
   }).then(res => res.json());
 }
+
+export function uploadTaskGoldenSet(owner, name, file) {
+  const formData = new FormData();
+  formData.append("file", file);
+  return fetch(`${Setting.ServerUrl}/api/upload-task-golden-set?id=${owner}/${encodeURIComponent(name)}`, {
+    method: "POST",
+    credentials: "include",
+    headers: {
+      "Accept-Language": Setting.getAcceptLanguage(),
+    },
+    body: formData,
+  }).then(res => res.json());
+}